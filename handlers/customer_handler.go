@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+)
+
+type CustomerHandler struct {
+	service *services.CustomerService
+}
+
+func NewCustomerHandler(service *services.CustomerService) *CustomerHandler {
+	return &CustomerHandler{service: service}
+}
+
+// HandleCustomers - GET/POST /api/customer
+func (h *CustomerHandler) HandleCustomers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll - GET /api/customer?q=&phone=&member_card=&limit=&offset=
+func (h *CustomerHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.CustomerFilter{
+		Search:     query.Get("q"),
+		Phone:      query.Get("phone"),
+		MemberCard: query.Get("member_card"),
+	}
+
+	var err error
+	if filter.Limit, filter.Offset, err = parsePagination(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.GetAll(filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (h *CustomerHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var customer models.Customer
+	err := json.NewDecoder(r.Body).Decode(&customer)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Create(&customer)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(customer)
+}
+
+// HandleCustomerByID - GET/PUT/DELETE /api/customer/{id}, GET /api/customer/{id}/transactions|points
+func (h *CustomerHandler) HandleCustomerByID(w http.ResponseWriter, r *http.Request) {
+	id, action, err := parseIDPath(r.URL.Path, "/api/customer/")
+	if err != nil {
+		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "transactions" && r.Method == http.MethodGet:
+		h.GetTransactions(w, r, id)
+	case action == "points" && r.Method == http.MethodGet:
+		h.GetPoints(w, r, id)
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "" && r.Method == http.MethodDelete:
+		h.Delete(w, id)
+	case action == "" || action == "transactions" || action == "points":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID - GET /api/customer/{id}
+func (h *CustomerHandler) GetByID(w http.ResponseWriter, id int) {
+	customer, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+}
+
+func (h *CustomerHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var customer models.Customer
+	err := json.NewDecoder(r.Body).Decode(&customer)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	customer.ID = id
+	err = h.service.Update(&customer)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+}
+
+// Delete - DELETE /api/customer/{id}
+func (h *CustomerHandler) Delete(w http.ResponseWriter, id int) {
+	err := h.service.Delete(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Customer deleted successfully",
+	})
+}
+
+// GetTransactions - GET /api/customer/{id}/transactions?limit=&offset=
+func (h *CustomerHandler) GetTransactions(w http.ResponseWriter, r *http.Request, id int) {
+	limit, offset, err := parsePagination(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.service.History(id, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetPoints - GET /api/customer/{id}/points?limit=&offset=
+func (h *CustomerHandler) GetPoints(w http.ResponseWriter, r *http.Request, id int) {
+	limit, offset, err := parsePagination(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	account, err := h.service.Points(id, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(account)
+}
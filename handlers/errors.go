@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"net/http"
+)
+
+// writeError maps service errors to HTTP status codes
+func writeError(w http.ResponseWriter, err error) {
+	var validationErr *models.ValidationError
+	var notFoundErr *models.NotFoundError
+	var conflictErr *models.ConflictError
+	var stockErr *models.InsufficientStockError
+	var printerErr *models.PrinterError
+	var unauthorizedErr *models.UnauthorizedError
+	var forbiddenErr *models.ForbiddenError
+
+	switch {
+	case errors.As(err, &stockErr):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]any{
+			"error":     "insufficient stock",
+			"shortages": stockErr.Shortages,
+		})
+	case errors.As(err, &validationErr):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.As(err, &notFoundErr):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.As(err, &conflictErr):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.As(err, &unauthorizedErr):
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.As(err, &forbiddenErr):
+		body := map[string]any{"error": "forbidden"}
+		if forbiddenErr.Permission != "" {
+			body["missing_permission"] = forbiddenErr.Permission
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(body)
+	case errors.As(err, &printerErr):
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
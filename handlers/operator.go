@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// operatorFromRequest returns who performed the request, the username of
+// the authenticated user
+func operatorFromRequest(r *http.Request) string {
+	if user := userFromRequest(r); user != nil {
+		return user.Username
+	}
+	return ""
+}
+
+// outletFromRequest returns the outlet the till belongs to, taken from the
+// X-Outlet-ID header, or the default outlet when the header is absent
+func outletFromRequest(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("X-Outlet-ID"))
+	if value == "" {
+		return models.DefaultOutletID, nil
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("Invalid X-Outlet-ID")
+	}
+	return id, nil
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+)
+
+type OutletHandler struct {
+	service *services.OutletService
+}
+
+func NewOutletHandler(service *services.OutletService) *OutletHandler {
+	return &OutletHandler{service: service}
+}
+
+// HandleOutlets - GET/POST /api/outlet
+func (h *OutletHandler) HandleOutlets(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *OutletHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outlets, err := h.service.GetAll()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(outlets)
+}
+
+func (h *OutletHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var outlet models.Outlet
+	err := json.NewDecoder(r.Body).Decode(&outlet)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Create(&outlet)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(outlet)
+}
+
+// HandleOutletByID - GET/PUT/DELETE /api/outlet/{id}
+func (h *OutletHandler) HandleOutletByID(w http.ResponseWriter, r *http.Request) {
+	id, action, err := parseIDPath(r.URL.Path, "/api/outlet/")
+	if err != nil {
+		http.Error(w, "Invalid outlet ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "" && r.Method == http.MethodDelete:
+		h.Delete(w, id)
+	case action == "":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID - GET /api/outlet/{id}
+func (h *OutletHandler) GetByID(w http.ResponseWriter, id int) {
+	outlet, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(outlet)
+}
+
+func (h *OutletHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var outlet models.Outlet
+	err := json.NewDecoder(r.Body).Decode(&outlet)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	outlet.ID = id
+	err = h.service.Update(&outlet)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(outlet)
+}
+
+// Delete - DELETE /api/outlet/{id}
+func (h *OutletHandler) Delete(w http.ResponseWriter, id int) {
+	err := h.service.Delete(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Outlet deleted successfully",
+	})
+}
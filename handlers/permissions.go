@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"path"
+)
+
+// PermissionRule requires Permission for requests with Method, or any
+// method when it is "*", to a URL path matching Path. Path is matched with
+// path.Match, so * stands for one path segment. A rule without a
+// Permission lets every request through, e.g. for login.
+type PermissionRule struct {
+	Method     string
+	Path       string
+	Permission string
+}
+
+// RequirePermissions checks the first rule matching each request against
+// the authenticated user. The path is cleaned first so a trailing slash
+// cannot dodge a rule. Reads matching no rule only need to be
+// authenticated, any other request matching no rule is refused.
+func RequirePermissions(rules []PermissionRule, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestPath := path.Clean("/" + r.URL.Path)
+
+		for _, rule := range rules {
+			if rule.Method != "*" && rule.Method != r.Method {
+				continue
+			}
+			if matched, _ := path.Match(rule.Path, requestPath); !matched {
+				continue
+			}
+
+			if rule.Permission == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			user := userFromRequest(r)
+			if user == nil {
+				writeError(w, models.NewUnauthorizedError("missing bearer token"))
+				return
+			}
+			if !user.HasPermission(rule.Permission) {
+				writeError(w, &models.ForbiddenError{Permission: rule.Permission})
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// tulis tanpa rule ditolak, baca cukup login
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeError(w, &models.ForbiddenError{})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"kasir-api/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequirePermissions(t *testing.T) {
+	rules := []PermissionRule{
+		{Method: http.MethodPost, Path: "/api/auth/login"},
+		{Method: http.MethodPost, Path: "/api/checkout", Permission: models.PermissionTransactionCreate},
+		{Method: http.MethodPost, Path: "/api/transaction/*/void", Permission: models.PermissionTransactionVoid},
+		{Method: "*", Path: "/api/user/*/*", Permission: models.PermissionUserManage},
+		{Method: "*", Path: "/api/role/*", Permission: models.PermissionUserManage},
+	}
+	cashier := &models.User{Username: "kasir", Permissions: []string{models.PermissionTransactionCreate}}
+	owner := &models.User{Username: "owner", Permissions: []string{models.PermissionTransactionCreate, models.PermissionTransactionVoid, models.PermissionUserManage}}
+
+	tests := []struct {
+		name   string
+		user   *models.User
+		method string
+		path   string
+		want   int
+	}{
+		{"allowed", cashier, http.MethodPost, "/api/checkout", http.StatusOK},
+		{"allowed with trailing slash", cashier, http.MethodPost, "/api/checkout/", http.StatusOK},
+		{"missing permission", cashier, http.MethodPost, "/api/transaction/7/void", http.StatusForbidden},
+		{"trailing slash still checked", cashier, http.MethodPost, "/api/transaction/7/void/", http.StatusForbidden},
+		{"own roles with trailing slash", cashier, http.MethodPut, "/api/user/5/roles/", http.StatusForbidden},
+		{"role with trailing slash", cashier, http.MethodPut, "/api/role/3/", http.StatusForbidden},
+		{"double slash", cashier, http.MethodPut, "/api//role/3", http.StatusForbidden},
+		{"dot segment", cashier, http.MethodPut, "/api/user/5/./roles", http.StatusForbidden},
+		{"manager may", owner, http.MethodPut, "/api/user/5/roles/", http.StatusOK},
+		{"no token", nil, http.MethodPost, "/api/transaction/7/void", http.StatusUnauthorized},
+		{"open rule without token", nil, http.MethodPost, "/api/auth/login", http.StatusOK},
+		{"unmatched write refused", cashier, http.MethodPost, "/api/unknown/1", http.StatusForbidden},
+		{"unmatched delete refused", owner, http.MethodDelete, "/api/unknown", http.StatusForbidden},
+		{"unmatched read allowed", cashier, http.MethodGet, "/api/unknown/1/", http.StatusOK},
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := RequirePermissions(rules, next)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "http://localhost"+tt.path, nil)
+			if tt.user != nil {
+				r = r.WithContext(context.WithValue(r.Context(), userContextKey, tt.user))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -2,22 +2,25 @@ package handlers
 
 import (
 	"encoding/json"
+	"io"
 	"kasir-api/models"
 	"kasir-api/services"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
 )
 
 type ProductHandler struct {
-	service *services.ProductService
+	service      *services.ProductService
+	stockService *services.StockMovementService
 }
 
-func NewProductHandler(service *services.ProductService) *ProductHandler {
-	return &ProductHandler{service: service}
+func NewProductHandler(service *services.ProductService, stockService *services.StockMovementService) *ProductHandler {
+	return &ProductHandler{service: service, stockService: stockService}
 }
 
-// HandleProducts - GET /api/product
+// HandleProducts - GET/POST /api/product
 func (h *ProductHandler) HandleProducts(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -29,15 +32,64 @@ func (h *ProductHandler) HandleProducts(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// GetAll - GET /api/product?q=&category_id=&min_price=&max_price=&in_stock=&sort=&order=&limit=&offset=
+// With limit or offset the response is a page, without both it is the plain
+// array of every matching product, as before paging was added.
 func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	products, err := h.service.GetAll()
+	query := r.URL.Query()
+	filter := models.ProductFilter{
+		Search: strings.TrimSpace(query.Get("q")),
+		Sort:   query.Get("sort"),
+		Order:  strings.ToLower(query.Get("order")),
+	}
+
+	var err error
+	if filter.CategoryID, err = parseOptionalInt(query, "category_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MinPrice, err = parseOptionalInt(query, "min_price"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MaxPrice, err = parseOptionalInt(query, "max_price"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if value := query.Get("in_stock"); value != "" {
+		filter.InStock, err = strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid in_stock", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// client lama tanpa paging tetap menerima array
+	if !query.Has("limit") && !query.Has("offset") {
+		products, err := h.service.List(filter)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(products)
+		return
+	}
+
+	if filter.Limit, filter.Offset, err = parsePagination(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.GetAll(filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(products)
+	json.NewEncoder(w).Encode(page)
 }
 
 func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -48,12 +100,18 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.Create(&product)
+	outletID, err := outletFromRequest(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	err = h.service.Create(&product, outletID, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(product)
@@ -61,6 +119,27 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 // HandleProductByID - GET/PUT/DELETE /api/product/{id}
 func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/product/barcode/"):
+		h.GetByBarcode(w, r)
+		return
+	case r.URL.Path == "/api/product/import":
+		h.Import(w, r)
+		return
+	case r.URL.Path == "/api/product/export":
+		h.Export(w, r)
+		return
+	case strings.HasSuffix(r.URL.Path, "/stock-history"):
+		h.GetStockHistory(w, r)
+		return
+	case strings.HasSuffix(r.URL.Path, "/stock-adjustment"):
+		h.AdjustStock(w, r)
+		return
+	case strings.HasSuffix(r.URL.Path, "/variants"):
+		h.GenerateVariants(w, r)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		h.GetByID(w, r)
@@ -84,7 +163,30 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	product, err := h.service.GetByID(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+// GetByBarcode - GET /api/product/barcode/{code}
+func (h *ProductHandler) GetByBarcode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	code := strings.TrimPrefix(r.URL.Path, "/api/product/barcode/")
+	if code == "" {
+		http.Error(w, "Invalid barcode", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.GetByBarcode(code)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -92,6 +194,77 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(product)
 }
 
+// maxImportSize limits the size of an uploaded CSV file
+const maxImportSize = 10 << 20
+
+// Import - POST /api/product/import?mode=create|upsert&dry_run=true
+// accepts a multipart "file" field or a raw text/csv body
+func (h *ProductHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	dryRun := false
+	if value := query.Get("dry_run"); value != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid dry_run", http.StatusBadRequest)
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Missing file field", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	outletID, err := outletFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.Import(body, query.Get("mode"), dryRun, outletID, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if len(result.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// Export - GET /api/product/export
+func (h *ProductHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="products.csv"`)
+	if err := h.service.Export(w); err != nil {
+		// header sudah terkirim, cukup dicatat
+		log.Println("product export failed:", err)
+	}
+}
+
 func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/product/")
 	id, err := strconv.Atoi(idStr)
@@ -107,13 +280,131 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	outletID, err := outletFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	product.ID = id
-	err = h.service.Update(&product)
+	err = h.service.Update(&product, outletID, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+// GetStockHistory - GET /api/product/{id}/stock-history?outlet_id=&limit=&offset=
+func (h *ProductHandler) GetStockHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/product/"), "/stock-history")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	outletID, err := parseOptionalInt(query, "outlet_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := parsePagination(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.stockService.GetByProduct(id, outletID, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// AdjustStock - POST /api/product/{id}/stock-adjustment
+func (h *ProductHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/product/"), "/stock-adjustment")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.StockAdjustmentRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	outletID, err := outletFromRequest(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	movement, err := h.stockService.Adjust(id, req, outletID, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+}
+
+// GenerateVariants - POST /api/product/{id}/variants, creates a variant for
+// every combination of option values
+func (h *ProductHandler) GenerateVariants(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/product/"), "/variants")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.VariantGenerateRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	outletID, err := outletFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.GenerateVariants(id, req, outletID, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(product)
 }
@@ -129,7 +420,7 @@ func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	err = h.service.Delete(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+)
+
+type PromotionHandler struct {
+	service *services.PromotionService
+}
+
+func NewPromotionHandler(service *services.PromotionService) *PromotionHandler {
+	return &PromotionHandler{service: service}
+}
+
+// HandlePromotions - GET/POST /api/promotion
+func (h *PromotionHandler) HandlePromotions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll - GET /api/promotion?active=true
+func (h *PromotionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	activeOnly := false
+	if value := r.URL.Query().Get("active"); value != "" {
+		var err error
+		activeOnly, err = strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid active", http.StatusBadRequest)
+			return
+		}
+	}
+
+	promotions, err := h.service.GetAll(activeOnly)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(promotions)
+}
+
+func (h *PromotionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	// promo baru aktif kecuali dikirim active=false
+	promotion := models.Promotion{Active: true}
+	err := json.NewDecoder(r.Body).Decode(&promotion)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Create(&promotion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(promotion)
+}
+
+// HandlePromotionByID - GET/PUT/DELETE /api/promotion/{id}
+func (h *PromotionHandler) HandlePromotionByID(w http.ResponseWriter, r *http.Request) {
+	id, action, err := parseIDPath(r.URL.Path, "/api/promotion/")
+	if err != nil {
+		http.Error(w, "Invalid promotion ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "" && r.Method == http.MethodDelete:
+		h.Delete(w, id)
+	case action == "":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID - GET /api/promotion/{id}
+func (h *PromotionHandler) GetByID(w http.ResponseWriter, id int) {
+	promotion, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(promotion)
+}
+
+func (h *PromotionHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	promotion := models.Promotion{Active: true}
+	err := json.NewDecoder(r.Body).Decode(&promotion)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	promotion.ID = id
+	err = h.service.Update(&promotion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(promotion)
+}
+
+// Delete - DELETE /api/promotion/{id}
+func (h *PromotionHandler) Delete(w http.ResponseWriter, id int) {
+	err := h.service.Delete(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Promotion deleted successfully",
+	})
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+)
+
+type PurchaseOrderHandler struct {
+	service *services.PurchaseOrderService
+}
+
+func NewPurchaseOrderHandler(service *services.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{service: service}
+}
+
+// HandlePurchaseOrders - GET/POST /api/purchase-order
+func (h *PurchaseOrderHandler) HandlePurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll - GET /api/purchase-order?supplier_id=&outlet_id=&status=&outstanding=true
+func (h *PurchaseOrderHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.PurchaseOrderFilter{Status: query.Get("status")}
+
+	var err error
+	if filter.SupplierID, err = parseOptionalInt(query, "supplier_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.OutletID, err = parseOptionalInt(query, "outlet_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if value := query.Get("outstanding"); value != "" {
+		filter.Outstanding, err = strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid outstanding", http.StatusBadRequest)
+			return
+		}
+	}
+
+	orders, err := h.service.GetAll(filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orders)
+}
+
+func (h *PurchaseOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var po models.PurchaseOrder
+	err := json.NewDecoder(r.Body).Decode(&po)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if po.OutletID == 0 {
+		po.OutletID, err = outletFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	err = h.service.Create(&po, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(po)
+}
+
+// HandlePurchaseOrderByID - GET /api/purchase-order/{id}, POST /api/purchase-order/{id}/receive|cancel
+func (h *PurchaseOrderHandler) HandlePurchaseOrderByID(w http.ResponseWriter, r *http.Request) {
+	id, action, err := parseIDPath(r.URL.Path, "/api/purchase-order/")
+	if err != nil {
+		http.Error(w, "Invalid purchase order ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, id)
+	case action == "receive" && r.Method == http.MethodPost:
+		h.Receive(w, r, id)
+	case action == "cancel" && r.Method == http.MethodPost:
+		h.Cancel(w, id)
+	case action == "" || action == "receive" || action == "cancel":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID - GET /api/purchase-order/{id}
+func (h *PurchaseOrderHandler) GetByID(w http.ResponseWriter, id int) {
+	po, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(po)
+}
+
+// Receive - POST /api/purchase-order/{id}/receive
+func (h *PurchaseOrderHandler) Receive(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.GoodsReceiptRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	receipt, err := h.service.Receive(id, req, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(receipt)
+}
+
+// Cancel - POST /api/purchase-order/{id}/cancel
+func (h *PurchaseOrderHandler) Cancel(w http.ResponseWriter, id int) {
+	po, err := h.service.Cancel(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(po)
+}
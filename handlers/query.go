@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// parseOptionalInt reads an integer query parameter, nil when absent
+func parseOptionalInt(query url.Values, key string) (*int, error) {
+	value := query.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s", key)
+	}
+	return &n, nil
+}
+
+// parsePagination reads limit and offset query parameters, zero when absent
+func parsePagination(query url.Values) (limit, offset int, err error) {
+	if value := query.Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return 0, 0, fmt.Errorf("Invalid limit")
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err = strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("Invalid offset")
+		}
+	}
+	return limit, offset, nil
+}
+
+// parseIDPath splits "/prefix/{id}/{action}" into the id and the optional
+// action segment
+func parseIDPath(path, prefix string) (int, string, error) {
+	rest := strings.Trim(strings.TrimPrefix(path, prefix), "/")
+	idStr, action, _ := strings.Cut(rest, "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, "", err
+	}
+	return id, action, nil
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+)
+
+type RoleHandler struct {
+	service *services.RoleService
+}
+
+func NewRoleHandler(service *services.RoleService) *RoleHandler {
+	return &RoleHandler{service: service}
+}
+
+// HandleRoles - GET/POST /api/role
+func (h *RoleHandler) HandleRoles(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *RoleHandler) GetAll(w http.ResponseWriter) {
+	roles, err := h.service.GetAll()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(roles)
+}
+
+func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var role models.Role
+	err := json.NewDecoder(r.Body).Decode(&role)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Create(&role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(role)
+}
+
+// HandleRoleByID - GET/PUT/DELETE /api/role/{id}
+func (h *RoleHandler) HandleRoleByID(w http.ResponseWriter, r *http.Request) {
+	id, action, err := parseIDPath(r.URL.Path, "/api/role/")
+	if err != nil {
+		http.Error(w, "Invalid role ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "" && r.Method == http.MethodDelete:
+		h.Delete(w, id)
+	case action == "":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID - GET /api/role/{id}
+func (h *RoleHandler) GetByID(w http.ResponseWriter, id int) {
+	role, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(role)
+}
+
+// Update - PUT /api/role/{id}, replaces the permissions
+func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var role models.Role
+	err := json.NewDecoder(r.Body).Decode(&role)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	role.ID = id
+	err = h.service.Update(&role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(role)
+}
+
+// Delete - DELETE /api/role/{id}
+func (h *RoleHandler) Delete(w http.ResponseWriter, id int) {
+	err := h.service.Delete(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Role deleted successfully",
+	})
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strings"
+)
+
+type ShiftHandler struct {
+	service *services.ShiftService
+}
+
+func NewShiftHandler(service *services.ShiftService) *ShiftHandler {
+	return &ShiftHandler{service: service}
+}
+
+// HandleShifts - GET/POST /api/shift
+func (h *ShiftHandler) HandleShifts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Open(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll - GET /api/shift?outlet_id=&cashier=&status=&limit=&offset=
+func (h *ShiftHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.ShiftFilter{
+		Cashier: strings.TrimSpace(query.Get("cashier")),
+		Status:  strings.ToLower(query.Get("status")),
+	}
+
+	var err error
+	if filter.OutletID, err = parseOptionalInt(query, "outlet_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Limit, filter.Offset, err = parsePagination(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.GetAll(filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// Open - POST /api/shift, the operator becomes the shift's cashier
+func (h *ShiftHandler) Open(w http.ResponseWriter, r *http.Request) {
+	var req models.OpenShiftRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	outletID, err := outletFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	shift, err := h.service.Open(req, outletID, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shift)
+}
+
+// HandleShiftByID - /api/shift/{id}[/close] and /api/shift/current
+func (h *ShiftHandler) HandleShiftByID(w http.ResponseWriter, r *http.Request) {
+	if strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/shift/"), "/") == "current" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetCurrent(w, r)
+		return
+	}
+
+	id, action, err := parseIDPath(r.URL.Path, "/api/shift/")
+	if err != nil {
+		http.Error(w, "Invalid shift ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, id)
+	case action == "close" && r.Method == http.MethodPost:
+		h.Close(w, r, id)
+	case action == "" || action == "close":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID - GET /api/shift/{id}
+func (h *ShiftHandler) GetByID(w http.ResponseWriter, id int) {
+	shift, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+}
+
+// GetCurrent - GET /api/shift/current, the operator's open shift at the outlet
+func (h *ShiftHandler) GetCurrent(w http.ResponseWriter, r *http.Request) {
+	outletID, err := outletFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	shift, err := h.service.GetCurrent(outletID, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+}
+
+// Close - POST /api/shift/{id}/close with the counted cash per denomination
+func (h *ShiftHandler) Close(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.CloseShiftRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// shift kasir lain hanya boleh ditutup oleh yang punya shift:manage
+	user := userFromRequest(r)
+	manage := user != nil && user.HasPermission(models.PermissionShiftManage)
+	shift, err := h.service.Close(id, req, operatorFromRequest(r), manage)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+}
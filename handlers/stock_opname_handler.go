@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+)
+
+type StockOpnameHandler struct {
+	service *services.StockOpnameService
+}
+
+func NewStockOpnameHandler(service *services.StockOpnameService) *StockOpnameHandler {
+	return &StockOpnameHandler{service: service}
+}
+
+// HandleOpnames - GET/POST /api/opname
+func (h *StockOpnameHandler) HandleOpnames(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Open(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll - GET /api/opname?status=
+func (h *StockOpnameHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	opnames, err := h.service.GetAll(r.URL.Query().Get("status"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(opnames)
+}
+
+// Open - POST /api/opname, snapshots system stock
+func (h *StockOpnameHandler) Open(w http.ResponseWriter, r *http.Request) {
+	var req models.OpenOpnameRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	outletID, err := outletFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opname, err := h.service.Open(req, outletID, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(opname)
+}
+
+// HandleOpnameByID - /api/opname/{id}[/counts|/variance|/post|/cancel]
+func (h *StockOpnameHandler) HandleOpnameByID(w http.ResponseWriter, r *http.Request) {
+	id, action, err := parseIDPath(r.URL.Path, "/api/opname/")
+	if err != nil {
+		http.Error(w, "Invalid opname ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, id)
+	case action == "counts" && r.Method == http.MethodPost:
+		h.SubmitCounts(w, r, id)
+	case action == "variance" && r.Method == http.MethodGet:
+		h.GetVariance(w, id)
+	case action == "post" && r.Method == http.MethodPost:
+		h.Post(w, r, id)
+	case action == "cancel" && r.Method == http.MethodPost:
+		h.Cancel(w, id)
+	case action == "" || action == "counts" || action == "variance" || action == "post" || action == "cancel":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID - GET /api/opname/{id}
+func (h *StockOpnameHandler) GetByID(w http.ResponseWriter, id int) {
+	opname, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(opname)
+}
+
+// SubmitCounts - POST /api/opname/{id}/counts
+func (h *StockOpnameHandler) SubmitCounts(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.OpnameCountRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	opname, err := h.service.SubmitCounts(id, req, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(opname)
+}
+
+// GetVariance - GET /api/opname/{id}/variance
+func (h *StockOpnameHandler) GetVariance(w http.ResponseWriter, id int) {
+	report, err := h.service.GetVariance(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// Post - POST /api/opname/{id}/post, adjusts stock for all counted products
+func (h *StockOpnameHandler) Post(w http.ResponseWriter, r *http.Request, id int) {
+	opname, err := h.service.Post(id, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(opname)
+}
+
+// Cancel - POST /api/opname/{id}/cancel
+func (h *StockOpnameHandler) Cancel(w http.ResponseWriter, id int) {
+	opname, err := h.service.Cancel(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(opname)
+}
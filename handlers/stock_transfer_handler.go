@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+)
+
+type StockTransferHandler struct {
+	service *services.StockTransferService
+}
+
+func NewStockTransferHandler(service *services.StockTransferService) *StockTransferHandler {
+	return &StockTransferHandler{service: service}
+}
+
+// HandleTransfers - GET/POST /api/transfer
+func (h *StockTransferHandler) HandleTransfers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll - GET /api/transfer?status=
+func (h *StockTransferHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	transfers, err := h.service.GetAll(r.URL.Query().Get("status"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfers)
+}
+
+// Create - POST /api/transfer, from_outlet_id defaults to the till's outlet
+func (h *StockTransferHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var transfer models.StockTransfer
+	err := json.NewDecoder(r.Body).Decode(&transfer)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if transfer.FromOutletID == 0 {
+		transfer.FromOutletID, err = outletFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	err = h.service.Create(&transfer, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// HandleTransferByID - /api/transfer/{id}[/receive|/cancel]
+func (h *StockTransferHandler) HandleTransferByID(w http.ResponseWriter, r *http.Request) {
+	id, action, err := parseIDPath(r.URL.Path, "/api/transfer/")
+	if err != nil {
+		http.Error(w, "Invalid transfer ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, id)
+	case action == "receive" && r.Method == http.MethodPost:
+		h.Receive(w, r, id)
+	case action == "cancel" && r.Method == http.MethodPost:
+		h.Cancel(w, r, id)
+	case action == "" || action == "receive" || action == "cancel":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID - GET /api/transfer/{id}
+func (h *StockTransferHandler) GetByID(w http.ResponseWriter, id int) {
+	transfer, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// Receive - POST /api/transfer/{id}/receive, books the stock at the destination
+func (h *StockTransferHandler) Receive(w http.ResponseWriter, r *http.Request, id int) {
+	transfer, err := h.service.Receive(id, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// Cancel - POST /api/transfer/{id}/cancel, returns the stock to the source
+func (h *StockTransferHandler) Cancel(w http.ResponseWriter, r *http.Request, id int) {
+	transfer, err := h.service.Cancel(id, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfer)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+)
+
+type SupplierHandler struct {
+	service *services.SupplierService
+}
+
+func NewSupplierHandler(service *services.SupplierService) *SupplierHandler {
+	return &SupplierHandler{service: service}
+}
+
+// HandleSuppliers - GET/POST /api/supplier
+func (h *SupplierHandler) HandleSuppliers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *SupplierHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	suppliers, err := h.service.GetAll()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suppliers)
+}
+
+func (h *SupplierHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var supplier models.Supplier
+	err := json.NewDecoder(r.Body).Decode(&supplier)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Create(&supplier)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(supplier)
+}
+
+// HandleSupplierByID - GET/PUT/DELETE /api/supplier/{id}, GET/POST /api/supplier/{id}/returns
+func (h *SupplierHandler) HandleSupplierByID(w http.ResponseWriter, r *http.Request) {
+	id, action, err := parseIDPath(r.URL.Path, "/api/supplier/")
+	if err != nil {
+		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "returns" && r.Method == http.MethodGet:
+		h.GetReturns(w, id)
+	case action == "returns" && r.Method == http.MethodPost:
+		h.CreateReturn(w, r, id)
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "" && r.Method == http.MethodDelete:
+		h.Delete(w, id)
+	case action == "" || action == "returns":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID - GET /api/supplier/{id}
+func (h *SupplierHandler) GetByID(w http.ResponseWriter, id int) {
+	supplier, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(supplier)
+}
+
+func (h *SupplierHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var supplier models.Supplier
+	err := json.NewDecoder(r.Body).Decode(&supplier)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	supplier.ID = id
+	err = h.service.Update(&supplier)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(supplier)
+}
+
+// Delete - DELETE /api/supplier/{id}
+func (h *SupplierHandler) Delete(w http.ResponseWriter, id int) {
+	err := h.service.Delete(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Supplier deleted successfully",
+	})
+}
+
+// GetReturns - GET /api/supplier/{id}/returns
+func (h *SupplierHandler) GetReturns(w http.ResponseWriter, id int) {
+	returns, err := h.service.GetReturns(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(returns)
+}
+
+// CreateReturn - POST /api/supplier/{id}/returns
+func (h *SupplierHandler) CreateReturn(w http.ResponseWriter, r *http.Request, id int) {
+	var ret models.SupplierReturn
+	err := json.NewDecoder(r.Body).Decode(&ret)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ret.SupplierID = id
+	if ret.OutletID == 0 {
+		ret.OutletID, err = outletFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	err = h.service.CreateReturn(&ret, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ret)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+)
+
+type TaxProfileHandler struct {
+	service *services.TaxProfileService
+}
+
+func NewTaxProfileHandler(service *services.TaxProfileService) *TaxProfileHandler {
+	return &TaxProfileHandler{service: service}
+}
+
+// HandleTaxProfiles - GET/POST /api/tax-profile
+func (h *TaxProfileHandler) HandleTaxProfiles(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *TaxProfileHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	profiles, err := h.service.GetAll()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profiles)
+}
+
+func (h *TaxProfileHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var profile models.TaxProfile
+	err := json.NewDecoder(r.Body).Decode(&profile)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Create(&profile)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(profile)
+}
+
+// HandleTaxProfileByID - GET/PUT/DELETE /api/tax-profile/{id}
+func (h *TaxProfileHandler) HandleTaxProfileByID(w http.ResponseWriter, r *http.Request) {
+	id, action, err := parseIDPath(r.URL.Path, "/api/tax-profile/")
+	if err != nil {
+		http.Error(w, "Invalid tax profile ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "" && r.Method == http.MethodDelete:
+		h.Delete(w, id)
+	case action == "":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID - GET /api/tax-profile/{id}
+func (h *TaxProfileHandler) GetByID(w http.ResponseWriter, id int) {
+	profile, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+func (h *TaxProfileHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var profile models.TaxProfile
+	err := json.NewDecoder(r.Body).Decode(&profile)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	profile.ID = id
+	err = h.service.Update(&profile)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// Delete - DELETE /api/tax-profile/{id}
+func (h *TaxProfileHandler) Delete(w http.ResponseWriter, id int) {
+	err := h.service.Delete(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Tax profile deleted successfully",
+	})
+}
@@ -2,17 +2,22 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"kasir-api/models"
 	"kasir-api/services"
 	"net/http"
+	"strings"
 )
 
 type TransactionHandler struct {
-	service *services.TransactionService
+	service  *services.TransactionService
+	receipts *services.ReceiptService
+	invoices *services.InvoiceService
 }
 
-func NewTransactionHandler(service *services.TransactionService) *TransactionHandler {
-	return &TransactionHandler{service: service}
+func NewTransactionHandler(service *services.TransactionService, receipts *services.ReceiptService, invoices *services.InvoiceService) *TransactionHandler {
+	return &TransactionHandler{service: service, receipts: receipts, invoices: invoices}
 }
 
 // multiple item apa aja, quantity nya
@@ -33,9 +38,99 @@ func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	transaction, err := h.service.Checkout(req.Items)
+	// outlet_id di body mengalahkan header X-Outlet-ID
+	outletID, err := outletFromRequest(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.OutletID != nil {
+		outletID = *req.OutletID
+	}
+
+	// retry dari till dengan Idempotency-Key yang sama tidak menjual dua kali
+	var idempotency *models.IdempotencyKey
+	if _, ok := r.Header["Idempotency-Key"]; ok {
+		idempotency = &models.IdempotencyKey{Key: r.Header.Get("Idempotency-Key")}
+	}
+
+	transaction, err := h.service.Checkout(outletID, req, operatorFromRequest(r), idempotency)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if idempotency != nil && idempotency.Replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transaction)
+}
+
+// HandleTransactions - GET /api/transaction
+func (h *TransactionHandler) HandleTransactions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll - GET /api/transaction?start_date=&end_date=&min_total=&max_total=&product_id=&outlet_id=&shift_id=&customer_id=&status=&limit=&offset=
+func (h *TransactionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.TransactionFilter{
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+		Status:    strings.ToLower(query.Get("status")),
+	}
+
+	var err error
+	if filter.MinTotal, err = parseOptionalInt(query, "min_total"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MaxTotal, err = parseOptionalInt(query, "max_total"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.ProductID, err = parseOptionalInt(query, "product_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.OutletID, err = parseOptionalInt(query, "outlet_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.ShiftID, err = parseOptionalInt(query, "shift_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.CustomerID, err = parseOptionalInt(query, "customer_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Limit, filter.Offset, err = parsePagination(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.GetAll(filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// GetByID - GET /api/transaction/{id}
+func (h *TransactionHandler) GetByID(w http.ResponseWriter, id int) {
+	transaction, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -43,13 +138,154 @@ func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(transaction)
 }
 
+// HandleTransactionByID - /api/transaction/{id}/...
+func (h *TransactionHandler) HandleTransactionByID(w http.ResponseWriter, r *http.Request) {
+	id, action, err := parseIDPath(r.URL.Path, "/api/transaction/")
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, id)
+	case action == "refund" && r.Method == http.MethodPost:
+		h.Refund(w, r, id)
+	case action == "void" && r.Method == http.MethodPost:
+		h.Void(w, r, id)
+	case action == "receipt" && r.Method == http.MethodGet:
+		h.Receipt(w, r, id)
+	case action == "print" && r.Method == http.MethodPost:
+		h.Print(w, r, id)
+	case action == "invoice.pdf" && r.Method == http.MethodGet:
+		h.Invoice(w, id)
+	case action == "", action == "refund", action == "void", action == "receipt", action == "print", action == "invoice.pdf":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Refund - POST /api/transaction/{id}/refund
+func (h *TransactionHandler) Refund(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.RefundRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	refund, err := h.service.Refund(id, req, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(refund)
+}
+
+// Void - POST /api/transaction/{id}/void
+func (h *TransactionHandler) Void(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.VoidRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.service.Void(id, req, operatorFromRequest(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transaction)
+}
+
+// Receipt - GET /api/transaction/{id}/receipt?format=text|escpos&paper=58|80
+func (h *TransactionHandler) Receipt(w http.ResponseWriter, r *http.Request, id int) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = models.ReceiptFormatText
+	}
+	paper, err := parseOptionalInt(query, "paper")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	paperWidth := 0
+	if paper != nil {
+		paperWidth = *paper
+	}
+
+	receipt, err := h.receipts.Render(id, format, paperWidth)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if format == models.ReceiptFormatESCPOS {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=receipt-%d.bin", id))
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.Write(receipt)
+}
+
+// Print - POST /api/transaction/{id}/print
+func (h *TransactionHandler) Print(w http.ResponseWriter, r *http.Request, id int) {
+	// body boleh kosong, pakai printer default
+	var req models.PrintRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	printer, isCopy, err := h.receipts.Print(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"message": "Receipt sent to printer",
+		"printer": printer,
+		"copy":    isCopy,
+	})
+}
+
+// Invoice - GET /api/transaction/{id}/invoice.pdf
+func (h *TransactionHandler) Invoice(w http.ResponseWriter, id int) {
+	invoice, err := h.invoices.PDF(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=invoice-%d.pdf", id))
+	w.Write(invoice)
+}
+
 func (h *TransactionHandler) HandleTodayReport(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	summary, err := h.service.GetTodaySalesSummary()
+	outletID, err := parseOptionalInt(r.URL.Query(), "outlet_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.service.GetTodaySalesSummary(outletID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -82,7 +318,13 @@ func (h *TransactionHandler) HandleDateRangeReport(w http.ResponseWriter, r *htt
 		return
 	}
 
-	summary, err := h.service.GetSalesSummaryByDateRange(startDate, endDate)
+	outletID, err := parseOptionalInt(query, "outlet_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.service.GetSalesSummaryByDateRange(startDate, endDate, outletID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -91,3 +333,73 @@ func (h *TransactionHandler) HandleDateRangeReport(w http.ResponseWriter, r *htt
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(summary)
 }
+
+// HandleProductSalesReport - GET /api/report/products?start_date=&end_date=&outlet_id=
+func (h *TransactionHandler) HandleProductSalesReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	startDate := query.Get("start_date")
+	endDate := query.Get("end_date")
+	if startDate == "" || endDate == "" {
+		http.Error(w, "start_date and end_date are required", http.StatusBadRequest)
+		return
+	}
+	if len(startDate) != 10 || len(endDate) != 10 {
+		http.Error(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	outletID, err := parseOptionalInt(query, "outlet_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sales, err := h.service.GetProductSales(startDate, endDate, outletID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sales)
+}
+
+// HandleTaxReport - GET /api/report/tax?start_date=&end_date=&outlet_id=
+func (h *TransactionHandler) HandleTaxReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	startDate := query.Get("start_date")
+	endDate := query.Get("end_date")
+	if startDate == "" || endDate == "" {
+		http.Error(w, "start_date and end_date are required", http.StatusBadRequest)
+		return
+	}
+	if len(startDate) != 10 || len(endDate) != 10 {
+		http.Error(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	outletID, err := parseOptionalInt(query, "outlet_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.GetTaxReport(startDate, endDate, outletID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"kasir-api/database"
 	"kasir-api/handlers"
+	"kasir-api/models"
 	"kasir-api/repositories"
 	"kasir-api/services"
 	"log"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -33,8 +35,119 @@ type APIInfoResponse struct {
 }
 
 type Config struct {
-	Port   string `mapstructure:"PORT"`
-	DBConn string `mapstructure:"DB_CONN"`
+	Port                 string        `mapstructure:"PORT"`
+	DBConn               string        `mapstructure:"DB_CONN"`
+	VoidWindow           time.Duration `mapstructure:"VOID_WINDOW"`           // e.g. 30m, empty allows voids on the day of the sale
+	IdempotencyRetention time.Duration `mapstructure:"IDEMPOTENCY_RETENTION"` // how long checkout Idempotency-Keys are kept, default 24h
+	ReceiptHeader        string        `mapstructure:"RECEIPT_HEADER"`        // lines separated by |
+	ReceiptFooter        string        `mapstructure:"RECEIPT_FOOTER"`        // lines separated by |
+	ReceiptPaper         int           `mapstructure:"RECEIPT_PAPER"`         // 58 or 80, default 58
+	PrinterAddr          string        `mapstructure:"PRINTER_ADDR"`          // default network printer, host:9100
+	Printers             string        `mapstructure:"PRINTERS"`              // named printers as name=host:port, separated by |
+	StoreNPWP            string        `mapstructure:"STORE_NPWP"`            // printed on invoices
+	JWTSecret            string        `mapstructure:"JWT_SECRET"`            // signs access and refresh tokens, required
+	AccessTokenTTL       time.Duration `mapstructure:"ACCESS_TOKEN_TTL"`      // default 15m
+	RefreshTokenTTL      time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`     // default 168h
+	LoginMaxAttempts     int           `mapstructure:"LOGIN_MAX_ATTEMPTS"`    // failed logins before lockout, default 5
+	LoginLockout         time.Duration `mapstructure:"LOGIN_LOCKOUT"`         // default 15m
+	AdminUsername        string        `mapstructure:"ADMIN_USERNAME"`        // first user, created when there are no users
+	AdminPassword        string        `mapstructure:"ADMIN_PASSWORD"`
+	LoyaltyPoints        int           `mapstructure:"LOYALTY_POINTS"`        // points earned per LOYALTY_SPEND_UNIT, default 1
+	LoyaltySpendUnit     int           `mapstructure:"LOYALTY_SPEND_UNIT"`    // rupiah, default 10000, 0 disables earning
+	LoyaltyPointValue    int           `mapstructure:"LOYALTY_POINT_VALUE"`   // rupiah off per redeemed point, default 100, 0 disables redeeming
+	LoyaltyPointsExpiry  time.Duration `mapstructure:"LOYALTY_POINTS_EXPIRY"` // default 8760h, 0 keeps points forever
+}
+
+// splitLines splits a | separated config value into trimmed, non-empty lines
+func splitLines(value string) []string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(value, "|") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// splitPrinters parses the PRINTERS config into printer names and addresses
+func splitPrinters(value string) (map[string]string, error) {
+	printers := make(map[string]string)
+	for _, entry := range splitLines(value) {
+		name, address, ok := strings.Cut(entry, "=")
+		name, address = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(address)
+		if !ok || name == "" || address == "" {
+			return nil, fmt.Errorf("printer %q is not name=host:port", entry)
+		}
+		printers[name] = address
+	}
+	return printers, nil
+}
+
+// routePermissions declares the permission each route needs, the first
+// matching rule applies. Reads without a rule are open to every user,
+// other requests without a rule are refused.
+var routePermissions = []handlers.PermissionRule{
+	{Method: http.MethodPost, Path: "/api/auth/login"},
+	{Method: http.MethodPost, Path: "/api/auth/refresh"},
+
+	{Method: http.MethodPost, Path: "/api/product/*/stock-adjustment", Permission: models.PermissionStockWrite},
+	{Method: http.MethodPost, Path: "/api/product", Permission: models.PermissionProductWrite},
+	{Method: http.MethodPost, Path: "/api/product/*", Permission: models.PermissionProductWrite}, // import
+	{Method: http.MethodPost, Path: "/api/product/*/variants", Permission: models.PermissionProductWrite},
+	{Method: http.MethodPut, Path: "/api/product/*", Permission: models.PermissionProductWrite},
+	{Method: http.MethodDelete, Path: "/api/product/*", Permission: models.PermissionProductWrite},
+	{Method: http.MethodPost, Path: "/api/category", Permission: models.PermissionProductWrite},
+	{Method: http.MethodPut, Path: "/api/category/*", Permission: models.PermissionProductWrite},
+	{Method: http.MethodDelete, Path: "/api/category/*", Permission: models.PermissionProductWrite},
+
+	{Method: http.MethodPost, Path: "/api/promotion", Permission: models.PermissionPromotionWrite},
+	{Method: http.MethodPut, Path: "/api/promotion/*", Permission: models.PermissionPromotionWrite},
+	{Method: http.MethodDelete, Path: "/api/promotion/*", Permission: models.PermissionPromotionWrite},
+
+	{Method: http.MethodPost, Path: "/api/outlet", Permission: models.PermissionSettingsWrite},
+	{Method: http.MethodPut, Path: "/api/outlet/*", Permission: models.PermissionSettingsWrite},
+	{Method: http.MethodDelete, Path: "/api/outlet/*", Permission: models.PermissionSettingsWrite},
+	{Method: http.MethodPost, Path: "/api/tax-profile", Permission: models.PermissionSettingsWrite},
+	{Method: http.MethodPut, Path: "/api/tax-profile/*", Permission: models.PermissionSettingsWrite},
+	{Method: http.MethodDelete, Path: "/api/tax-profile/*", Permission: models.PermissionSettingsWrite},
+
+	{Method: http.MethodPost, Path: "/api/transfer", Permission: models.PermissionStockWrite},
+	{Method: http.MethodPost, Path: "/api/transfer/*/*", Permission: models.PermissionStockWrite},
+	{Method: http.MethodPost, Path: "/api/opname", Permission: models.PermissionStockWrite},
+	{Method: http.MethodPost, Path: "/api/opname/*/*", Permission: models.PermissionStockWrite},
+
+	{Method: http.MethodPost, Path: "/api/supplier", Permission: models.PermissionPurchasingWrite},
+	{Method: http.MethodPut, Path: "/api/supplier/*", Permission: models.PermissionPurchasingWrite},
+	{Method: http.MethodDelete, Path: "/api/supplier/*", Permission: models.PermissionPurchasingWrite},
+	{Method: http.MethodPost, Path: "/api/supplier/*/returns", Permission: models.PermissionPurchasingWrite},
+	{Method: http.MethodPost, Path: "/api/purchase-order", Permission: models.PermissionPurchasingWrite},
+	{Method: http.MethodPost, Path: "/api/purchase-order/*/*", Permission: models.PermissionPurchasingWrite},
+
+	{Method: http.MethodPost, Path: "/api/checkout", Permission: models.PermissionTransactionCreate},
+	{Method: http.MethodPost, Path: "/api/customer", Permission: models.PermissionTransactionCreate},
+	{Method: http.MethodPut, Path: "/api/customer/*", Permission: models.PermissionTransactionCreate},
+	{Method: http.MethodDelete, Path: "/api/customer/*", Permission: models.PermissionSettingsWrite},
+	{Method: http.MethodPost, Path: "/api/transaction/*/print", Permission: models.PermissionTransactionCreate},
+	{Method: http.MethodPost, Path: "/api/transaction/*/refund", Permission: models.PermissionTransactionRefund},
+	{Method: http.MethodPost, Path: "/api/transaction/*/void", Permission: models.PermissionTransactionVoid},
+	{Method: http.MethodGet, Path: "/api/transaction", Permission: models.PermissionReportRead},
+	{Method: http.MethodGet, Path: "/api/transaction/*", Permission: models.PermissionReportRead},
+	{Method: http.MethodGet, Path: "/api/transaction/*/receipt", Permission: models.PermissionTransactionCreate}, // kasir boleh cetak ulang struk
+	{Method: http.MethodGet, Path: "/api/transaction/*/invoice.pdf", Permission: models.PermissionReportRead},
+	{Method: "*", Path: "/api/report", Permission: models.PermissionReportRead},
+	{Method: "*", Path: "/api/report/*", Permission: models.PermissionReportRead},
+
+	{Method: http.MethodGet, Path: "/api/shift/current", Permission: models.PermissionShiftWrite},
+	{Method: http.MethodPost, Path: "/api/shift", Permission: models.PermissionShiftWrite},
+	{Method: http.MethodPost, Path: "/api/shift/*/close", Permission: models.PermissionShiftWrite},
+	{Method: http.MethodGet, Path: "/api/shift", Permission: models.PermissionShiftRead},
+	{Method: http.MethodGet, Path: "/api/shift/*", Permission: models.PermissionShiftRead},
+
+	{Method: "*", Path: "/api/user", Permission: models.PermissionUserManage},
+	{Method: "*", Path: "/api/user/*", Permission: models.PermissionUserManage},
+	{Method: "*", Path: "/api/user/*/*", Permission: models.PermissionUserManage},
+	{Method: "*", Path: "/api/role", Permission: models.PermissionUserManage},
+	{Method: "*", Path: "/api/role/*", Permission: models.PermissionUserManage},
 }
 
 // getEnv retrieves environment variable or returns default value
@@ -56,44 +169,380 @@ func handleAPIInfo(w http.ResponseWriter, r *http.Request) {
 	// Build endpoint metadata for current implementation
 	endpoints := map[string]EndpointGroup{
 		"GET": {
+			"me": {
+				Path:        "/api/auth/me",
+				Description: "the authenticated user",
+			},
+			"list_users": {
+				Path:        "/api/user",
+				Description: "get all users",
+			},
+			"get_user": {
+				Path:        "/api/user/{id}",
+				Description: "get a single user with roles and permissions",
+			},
+			"list_roles": {
+				Path:        "/api/role",
+				Description: "get all roles with their permissions",
+			},
+			"get_role": {
+				Path:        "/api/role/{id}",
+				Description: "get a single role",
+			},
 			"list_products": {
 				Path:        "/api/product",
-				Description: "get all products",
+				Description: "list products (q, category_id, min_price, max_price, in_stock, sort, order, limit, offset query params), a page with limit or offset, otherwise an array of all products",
 			},
 			"get_product": {
 				Path:        "/api/product/{id}",
 				Description: "get a single product",
 			},
+			"get_product_by_barcode": {
+				Path:        "/api/product/barcode/{code}",
+				Description: "look up a product by barcode",
+			},
+			"export_products": {
+				Path:        "/api/product/export",
+				Description: "download the catalog as CSV (sku, name, price, stock, category_id, barcodes)",
+			},
+			"stock_history": {
+				Path:        "/api/product/{id}/stock-history",
+				Description: "list stock movements of a product (outlet_id, limit, offset query params)",
+			},
+			"list_promotions": {
+				Path:        "/api/promotion",
+				Description: "list promotion rules (active query param)",
+			},
+			"get_promotion": {
+				Path:        "/api/promotion/{id}",
+				Description: "get a single promotion rule",
+			},
+			"list_tax_profiles": {
+				Path:        "/api/tax-profile",
+				Description: "get all tax profiles (PPN, PB1, ...)",
+			},
+			"get_tax_profile": {
+				Path:        "/api/tax-profile/{id}",
+				Description: "get a single tax profile",
+			},
+			"list_transactions": {
+				Path:        "/api/transaction",
+				Description: "list transactions newest first (start_date, end_date, min_total, max_total, product_id, outlet_id, shift_id, customer_id, status, limit, offset query params)",
+			},
+			"get_transaction": {
+				Path:        "/api/transaction/{id}",
+				Description: "get a transaction with its lines, payments, discounts and taxes",
+			},
+			"transaction_receipt": {
+				Path:        "/api/transaction/{id}/receipt",
+				Description: "render the receipt for 58mm or 80mm paper (format=text|escpos, paper=58|80 query params), printed receipts are marked COPY",
+			},
+			"transaction_invoice": {
+				Path:        "/api/transaction/{id}/invoice.pdf",
+				Description: "A4 PDF invoice with taxes, amount in words (terbilang) and the buyer captured at checkout",
+			},
+			"list_outlets": {
+				Path:        "/api/outlet",
+				Description: "get all outlets",
+			},
+			"get_outlet": {
+				Path:        "/api/outlet/{id}",
+				Description: "get a single outlet",
+			},
+			"list_transfers": {
+				Path:        "/api/transfer",
+				Description: "list stock transfers between outlets (status query param)",
+			},
+			"get_transfer": {
+				Path:        "/api/transfer/{id}",
+				Description: "get a stock transfer with its items",
+			},
+			"list_categories": {
+				Path:        "/api/category",
+				Description: "get all categories",
+			},
+			"get_category": {
+				Path:        "/api/category/{id}",
+				Description: "get a single category",
+			},
+			"list_opnames": {
+				Path:        "/api/opname",
+				Description: "list stock opname sessions (status query param)",
+			},
+			"get_opname": {
+				Path:        "/api/opname/{id}",
+				Description: "get a stock opname session",
+			},
+			"opname_variance": {
+				Path:        "/api/opname/{id}/variance",
+				Description: "variance per product and category of a stock opname",
+			},
+			"list_shifts": {
+				Path:        "/api/shift",
+				Description: "list cashier shifts newest first (outlet_id, cashier, status, limit, offset query params)",
+			},
+			"current_shift": {
+				Path:        "/api/shift/current",
+				Description: "the operator's open shift at the outlet with live expected cash",
+			},
+			"get_shift": {
+				Path:        "/api/shift/{id}",
+				Description: "get a shift with expected cash, counted cash per denomination and over/short",
+			},
+			"list_customers": {
+				Path:        "/api/customer",
+				Description: "list customers (q searches name, phone or member card; phone, member_card, limit, offset query params)",
+			},
+			"get_customer": {
+				Path:        "/api/customer/{id}",
+				Description: "get a single customer",
+			},
+			"customer_transactions": {
+				Path:        "/api/customer/{id}/transactions",
+				Description: "purchase history of a customer with lifetime spend and visit count (limit, offset query params)",
+			},
+			"customer_points": {
+				Path:        "/api/customer/{id}/points",
+				Description: "loyalty points balance of a customer with the points ledger (limit, offset query params)",
+			},
+			"list_suppliers": {
+				Path:        "/api/supplier",
+				Description: "get all suppliers",
+			},
+			"get_supplier": {
+				Path:        "/api/supplier/{id}",
+				Description: "get a single supplier",
+			},
+			"list_supplier_returns": {
+				Path:        "/api/supplier/{id}/returns",
+				Description: "list returns sent to a supplier",
+			},
+			"list_purchase_orders": {
+				Path:        "/api/purchase-order",
+				Description: "list purchase orders (supplier_id, outlet_id, status, outstanding query params)",
+			},
+			"get_purchase_order": {
+				Path:        "/api/purchase-order/{id}",
+				Description: "get a purchase order with items and goods receipts",
+			},
 			"health": {
 				Path:        "/health",
 				Description: "health check endpoint",
 			},
 			"today_report": {
 				Path:        "/api/report/today",
-				Description: "get today's sales summary",
+				Description: "get today's sales summary net of refunds (outlet_id query param, all outlets when absent)",
 			},
 			"date_range_report": {
 				Path:        "/api/report",
-				Description: "get sales summary by date range (start_date, end_date query params)",
+				Description: "get sales summary by date range net of refunds (start_date, end_date, outlet_id query params)",
+			},
+			"product_sales_report": {
+				Path:        "/api/report/products",
+				Description: "quantity and revenue per product, variants rolled up to their parent (start_date, end_date, outlet_id query params)",
+			},
+			"tax_report": {
+				Path:        "/api/report/tax",
+				Description: "tax collected per month and tax profile, plus service charge, for filing (start_date, end_date, outlet_id query params)",
 			},
 		},
 		"POST": {
+			"login": {
+				Path:        "/api/auth/login",
+				Description: "log in with username and password, returns access and refresh tokens; locks the account after LOGIN_MAX_ATTEMPTS failures",
+			},
+			"refresh_token": {
+				Path:        "/api/auth/refresh",
+				Description: "exchange a refresh_token for new tokens",
+			},
+			"create_user": {
+				Path:        "/api/user",
+				Description: "create a user (username, password, active, roles)",
+			},
+			"create_role": {
+				Path:        "/api/role",
+				Description: "create a role with a set of permissions (product:write, transaction:void, report:read, ...)",
+			},
 			"create_product": {
 				Path:        "/api/product",
 				Description: "create a new product",
 			},
+			"import_products": {
+				Path:        "/api/product/import",
+				Description: "import products from CSV (mode=create|upsert, dry_run query params)",
+			},
+			"adjust_stock": {
+				Path:        "/api/product/{id}/stock-adjustment",
+				Description: "record a manual stock change (outlet_id, delta, reason: adjustment|restock|correction, note)",
+			},
+			"generate_variants": {
+				Path:        "/api/product/{id}/variants",
+				Description: "set option axes (e.g. size, flavour) and create a variant per combination",
+			},
+			"create_promotion": {
+				Path:        "/api/promotion",
+				Description: "create a promotion (percentage, fixed, buy_x_get_y, bundle; item, category or cart scope; min_spend, time window, coupon)",
+			},
+			"create_tax_profile": {
+				Path:        "/api/tax-profile",
+				Description: "create a tax profile (name, rate in percent, inclusive), assign it with tax_profile_id on products or categories",
+			},
+			"refund_transaction": {
+				Path:        "/api/transaction/{id}/refund",
+				Description: "return items of a sale (detail_id or product_id, quantity, damaged), creates a linked negative transaction",
+			},
+			"void_transaction": {
+				Path:        "/api/transaction/{id}/void",
+				Description: "void a sale with a reason, restores stock; allowed within VOID_WINDOW or on the day of the sale",
+			},
+			"print_receipt": {
+				Path:        "/api/transaction/{id}/print",
+				Description: "send the receipt as ESC/POS to PRINTER_ADDR or a printer named in PRINTERS (printer, paper, open_drawer), reprints are marked COPY",
+			},
+			"create_outlet": {
+				Path:        "/api/outlet",
+				Description: "create a new outlet",
+			},
+			"create_transfer": {
+				Path:        "/api/transfer",
+				Description: "move stock to another outlet, in transit until received",
+			},
+			"receive_transfer": {
+				Path:        "/api/transfer/{id}/receive",
+				Description: "book an in-transit transfer into the destination outlet",
+			},
+			"cancel_transfer": {
+				Path:        "/api/transfer/{id}/cancel",
+				Description: "return an in-transit transfer to the source outlet",
+			},
+			"open_opname": {
+				Path:        "/api/opname",
+				Description: "open a stock opname session and snapshot system stock (optional category_id)",
+			},
+			"submit_opname_counts": {
+				Path:        "/api/opname/{id}/counts",
+				Description: "submit counted quantities (mode: set|add)",
+			},
+			"post_opname": {
+				Path:        "/api/opname/{id}/post",
+				Description: "apply the counted variances to stock",
+			},
+			"cancel_opname": {
+				Path:        "/api/opname/{id}/cancel",
+				Description: "cancel an open stock opname session",
+			},
+			"open_shift": {
+				Path:        "/api/shift",
+				Description: "open a shift for the operator with an opening_float, checkouts and refunds by the operator are tied to it",
+			},
+			"close_shift": {
+				Path:        "/api/shift/{id}/close",
+				Description: "close a shift with cash_counts per denomination, reports expected cash and over/short; another cashier's shift needs shift:manage",
+			},
+			"create_customer": {
+				Path:        "/api/customer",
+				Description: "register a customer with name, phone, member_card, email and note",
+			},
+			"create_supplier": {
+				Path:        "/api/supplier",
+				Description: "create a new supplier",
+			},
+			"create_supplier_return": {
+				Path:        "/api/supplier/{id}/returns",
+				Description: "return goods to a supplier, reduces stock",
+			},
+			"create_purchase_order": {
+				Path:        "/api/purchase-order",
+				Description: "create a purchase order with line items and expected cost",
+			},
+			"receive_purchase_order": {
+				Path:        "/api/purchase-order/{id}/receive",
+				Description: "post a (partial) goods receipt, increases stock",
+			},
+			"cancel_purchase_order": {
+				Path:        "/api/purchase-order/{id}/cancel",
+				Description: "cancel an outstanding purchase order",
+			},
+			"create_category": {
+				Path:        "/api/category",
+				Description: "create a new category (optional parent_id and points_multiplier for loyalty points)",
+			},
 		},
 		"PUT": {
+			"update_user": {
+				Path:        "/api/user/{id}",
+				Description: "update active and optionally the password, the username cannot be changed",
+			},
+			"set_user_roles": {
+				Path:        "/api/user/{id}/roles",
+				Description: "replace the roles of a user (roles: [\"cashier\", ...])",
+			},
+			"update_role": {
+				Path:        "/api/role/{id}",
+				Description: "update a role's name, description and permissions",
+			},
 			"update_product": {
 				Path:        "/api/product/{id}",
 				Description: "update all fields",
 			},
+			"update_promotion": {
+				Path:        "/api/promotion/{id}",
+				Description: "update a promotion rule",
+			},
+			"update_tax_profile": {
+				Path:        "/api/tax-profile/{id}",
+				Description: "update a tax profile, past transactions keep their rate",
+			},
+			"update_outlet": {
+				Path:        "/api/outlet/{id}",
+				Description: "update outlet name, address, service_charge_rate, rounding_unit and rounding_mode",
+			},
+			"update_customer": {
+				Path:        "/api/customer/{id}",
+				Description: "update customer details",
+			},
+			"update_supplier": {
+				Path:        "/api/supplier/{id}",
+				Description: "update supplier details",
+			},
+			"update_category": {
+				Path:        "/api/category/{id}",
+				Description: "update category name, description, parent and points_multiplier",
+			},
 		},
 		"DELETE": {
+			"delete_role": {
+				Path:        "/api/role/{id}",
+				Description: "delete a role that no user has",
+			},
 			"delete_product": {
 				Path:        "/api/product/{id}",
 				Description: "delete a product",
 			},
+			"delete_promotion": {
+				Path:        "/api/promotion/{id}",
+				Description: "delete a promotion that was never used",
+			},
+			"delete_tax_profile": {
+				Path:        "/api/tax-profile/{id}",
+				Description: "delete a tax profile that is not in use",
+			},
+			"delete_outlet": {
+				Path:        "/api/outlet/{id}",
+				Description: "delete an outlet without stock or sales",
+			},
+			"delete_customer": {
+				Path:        "/api/customer/{id}",
+				Description: "delete a customer without transactions",
+			},
+			"delete_supplier": {
+				Path:        "/api/supplier/{id}",
+				Description: "delete a supplier without purchase history",
+			},
+			"delete_category": {
+				Path:        "/api/category/{id}",
+				Description: "delete a category (reassign_to query param moves its products)",
+			},
 		},
 	}
 
@@ -116,6 +565,15 @@ func handleAPIInfo(w http.ResponseWriter, r *http.Request) {
 func main() {
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.SetDefault("IDEMPOTENCY_RETENTION", "24h")
+	viper.SetDefault("ACCESS_TOKEN_TTL", "15m")
+	viper.SetDefault("REFRESH_TOKEN_TTL", "168h")
+	viper.SetDefault("LOGIN_MAX_ATTEMPTS", 5)
+	viper.SetDefault("LOGIN_LOCKOUT", "15m")
+	viper.SetDefault("LOYALTY_POINTS", 1)
+	viper.SetDefault("LOYALTY_SPEND_UNIT", 10000)
+	viper.SetDefault("LOYALTY_POINT_VALUE", 100)
+	viper.SetDefault("LOYALTY_POINTS_EXPIRY", "8760h")
 
 	if _, err := os.Stat(".env"); err == nil {
 		viper.SetConfigFile(".env")
@@ -123,8 +581,30 @@ func main() {
 	}
 
 	config := Config{
-		Port:   viper.GetString("PORT"),
-		DBConn: viper.GetString("DB_CONN"),
+		Port:                 viper.GetString("PORT"),
+		DBConn:               viper.GetString("DB_CONN"),
+		VoidWindow:           viper.GetDuration("VOID_WINDOW"),
+		IdempotencyRetention: viper.GetDuration("IDEMPOTENCY_RETENTION"),
+		ReceiptHeader:        viper.GetString("RECEIPT_HEADER"),
+		ReceiptFooter:        viper.GetString("RECEIPT_FOOTER"),
+		ReceiptPaper:         viper.GetInt("RECEIPT_PAPER"),
+		PrinterAddr:          viper.GetString("PRINTER_ADDR"),
+		Printers:             viper.GetString("PRINTERS"),
+		StoreNPWP:            viper.GetString("STORE_NPWP"),
+		JWTSecret:            viper.GetString("JWT_SECRET"),
+		AccessTokenTTL:       viper.GetDuration("ACCESS_TOKEN_TTL"),
+		RefreshTokenTTL:      viper.GetDuration("REFRESH_TOKEN_TTL"),
+		LoginMaxAttempts:     viper.GetInt("LOGIN_MAX_ATTEMPTS"),
+		LoginLockout:         viper.GetDuration("LOGIN_LOCKOUT"),
+		AdminUsername:        viper.GetString("ADMIN_USERNAME"),
+		AdminPassword:        viper.GetString("ADMIN_PASSWORD"),
+		LoyaltyPoints:        viper.GetInt("LOYALTY_POINTS"),
+		LoyaltySpendUnit:     viper.GetInt("LOYALTY_SPEND_UNIT"),
+		LoyaltyPointValue:    viper.GetInt("LOYALTY_POINT_VALUE"),
+		LoyaltyPointsExpiry:  viper.GetDuration("LOYALTY_POINTS_EXPIRY"),
+	}
+	if config.JWTSecret == "" {
+		log.Fatal("JWT_SECRET must be set")
 	}
 
 	// setup database connection
@@ -133,27 +613,144 @@ func main() {
 		log.Fatal("Failed to initialize Database:", err)
 	}
 	defer db.Close()
+
+	userRepo := repositories.NewUserRepository(db)
+	userService := services.NewUserService(userRepo)
+	if admin, err := userService.Bootstrap(config.AdminUsername, config.AdminPassword); err != nil {
+		log.Fatal("Failed to create the first user:", err)
+	} else if admin != nil {
+		fmt.Println("Created first user " + admin.Username)
+	}
+	userHandler := handlers.NewUserHandler(userService)
+	http.HandleFunc("/api/user", userHandler.HandleUsers)
+	http.HandleFunc("/api/user/", userHandler.HandleUserByID)
+
+	roleRepo := repositories.NewRoleRepository(db)
+	roleService := services.NewRoleService(roleRepo)
+	roleHandler := handlers.NewRoleHandler(roleService)
+	http.HandleFunc("/api/role", roleHandler.HandleRoles)
+	http.HandleFunc("/api/role/", roleHandler.HandleRoleByID)
+
+	authService := services.NewAuthService(userRepo, services.AuthConfig{
+		Secret:      []byte(config.JWTSecret),
+		AccessTTL:   config.AccessTokenTTL,
+		RefreshTTL:  config.RefreshTokenTTL,
+		MaxAttempts: config.LoginMaxAttempts,
+		Lockout:     config.LoginLockout,
+	})
+	authHandler := handlers.NewAuthHandler(authService)
+	http.HandleFunc("/api/auth/login", authHandler.Login)
+	http.HandleFunc("/api/auth/refresh", authHandler.Refresh)
+	http.HandleFunc("/api/auth/me", authHandler.Me)
 	
 	productRepo := repositories.NewProductRepository(db)
 	productService := services.NewProductService(productRepo)
-	productHandler := handlers.NewProductHandler(productService)
+	stockMovementRepo := repositories.NewStockMovementRepository(db)
+	stockMovementService := services.NewStockMovementService(stockMovementRepo)
+	productHandler := handlers.NewProductHandler(productService, stockMovementService)
 
 	// setup routes
 	http.HandleFunc("/api/product", productHandler.HandleProducts)
 	http.HandleFunc("/api/product/", productHandler.HandleProductByID)
+
+	outletRepo := repositories.NewOutletRepository(db)
+	outletService := services.NewOutletService(outletRepo)
+	outletHandler := handlers.NewOutletHandler(outletService)
+	http.HandleFunc("/api/outlet", outletHandler.HandleOutlets)
+	http.HandleFunc("/api/outlet/", outletHandler.HandleOutletByID)
+
+	promotionRepo := repositories.NewPromotionRepository(db)
+	promotionService := services.NewPromotionService(promotionRepo)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
+	http.HandleFunc("/api/promotion", promotionHandler.HandlePromotions)
+	http.HandleFunc("/api/promotion/", promotionHandler.HandlePromotionByID)
+
+	taxProfileRepo := repositories.NewTaxProfileRepository(db)
+	taxProfileService := services.NewTaxProfileService(taxProfileRepo)
+	taxProfileHandler := handlers.NewTaxProfileHandler(taxProfileService)
+	http.HandleFunc("/api/tax-profile", taxProfileHandler.HandleTaxProfiles)
+	http.HandleFunc("/api/tax-profile/", taxProfileHandler.HandleTaxProfileByID)
+
+	stockTransferRepo := repositories.NewStockTransferRepository(db)
+	stockTransferService := services.NewStockTransferService(stockTransferRepo)
+	stockTransferHandler := handlers.NewStockTransferHandler(stockTransferService)
+	http.HandleFunc("/api/transfer", stockTransferHandler.HandleTransfers)
+	http.HandleFunc("/api/transfer/", stockTransferHandler.HandleTransferByID)
+
+	categoryRepo := repositories.NewCategoryRepository(db)
+	categoryService := services.NewCategoryService(categoryRepo)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	http.HandleFunc("/api/category", categoryHandler.HandleCategories)
+	http.HandleFunc("/api/category/", categoryHandler.HandleCategoryByID)
+
+	stockOpnameRepo := repositories.NewStockOpnameRepository(db)
+	stockOpnameService := services.NewStockOpnameService(stockOpnameRepo)
+	stockOpnameHandler := handlers.NewStockOpnameHandler(stockOpnameService)
+	http.HandleFunc("/api/opname", stockOpnameHandler.HandleOpnames)
+	http.HandleFunc("/api/opname/", stockOpnameHandler.HandleOpnameByID)
+
+	shiftRepo := repositories.NewShiftRepository(db)
+	shiftService := services.NewShiftService(shiftRepo)
+	shiftHandler := handlers.NewShiftHandler(shiftService)
+	http.HandleFunc("/api/shift", shiftHandler.HandleShifts)
+	http.HandleFunc("/api/shift/", shiftHandler.HandleShiftByID)
+
+	supplierRepo := repositories.NewSupplierRepository(db)
+	supplierService := services.NewSupplierService(supplierRepo)
+	supplierHandler := handlers.NewSupplierHandler(supplierService)
+	http.HandleFunc("/api/supplier", supplierHandler.HandleSuppliers)
+	http.HandleFunc("/api/supplier/", supplierHandler.HandleSupplierByID)
+
+	purchaseOrderRepo := repositories.NewPurchaseOrderRepository(db)
+	purchaseOrderService := services.NewPurchaseOrderService(purchaseOrderRepo)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(purchaseOrderService)
+	http.HandleFunc("/api/purchase-order", purchaseOrderHandler.HandlePurchaseOrders)
+	http.HandleFunc("/api/purchase-order/", purchaseOrderHandler.HandlePurchaseOrderByID)
+
 	transactionRepo := repositories.NewTransactionRepository(db)
-	transactionService := services.NewTransactionService(transactionRepo)
-	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	transactionService := services.NewTransactionService(transactionRepo, config.VoidWindow, config.IdempotencyRetention, models.LoyaltyConfig{
+		PointsPerUnit: config.LoyaltyPoints,
+		SpendUnit:     config.LoyaltySpendUnit,
+		PointValue:    config.LoyaltyPointValue,
+		Expiry:        config.LoyaltyPointsExpiry,
+	})
+	printers, err := splitPrinters(config.Printers)
+	if err != nil {
+		log.Fatal("Invalid PRINTERS:", err)
+	}
+	receiptService := services.NewReceiptService(transactionRepo, outletRepo, services.ReceiptConfig{
+		Header:   splitLines(config.ReceiptHeader),
+		Footer:   splitLines(config.ReceiptFooter),
+		Paper:    config.ReceiptPaper,
+		Printer:  config.PrinterAddr,
+		Printers: printers,
+	})
+	invoiceService := services.NewInvoiceService(transactionRepo, outletRepo, services.InvoiceConfig{
+		Header: splitLines(config.ReceiptHeader),
+		Footer: splitLines(config.ReceiptFooter),
+		NPWP:   config.StoreNPWP,
+	})
+	transactionHandler := handlers.NewTransactionHandler(transactionService, receiptService, invoiceService)
 	http.HandleFunc("/api/info", handleAPIInfo)
 
 	// checkout endpoint
 	http.HandleFunc("/api/checkout", transactionHandler.HandleCheckout)
+	http.HandleFunc("/api/transaction", transactionHandler.HandleTransactions)
+	http.HandleFunc("/api/transaction/", transactionHandler.HandleTransactionByID)
 
 	// today report endpoint
 	http.HandleFunc("/api/report/today", transactionHandler.HandleTodayReport)
 
 	// date range report endpoint
 	http.HandleFunc("/api/report", transactionHandler.HandleDateRangeReport)
+	http.HandleFunc("/api/report/products", transactionHandler.HandleProductSalesReport)
+	http.HandleFunc("/api/report/tax", transactionHandler.HandleTaxReport)
+
+	customerRepo := repositories.NewCustomerRepository(db)
+	customerService := services.NewCustomerService(customerRepo, transactionRepo)
+	customerHandler := handlers.NewCustomerHandler(customerService)
+	http.HandleFunc("/api/customer", customerHandler.HandleCustomers)
+	http.HandleFunc("/api/customer/", customerHandler.HandleCustomerByID)
 		
 	// localhost:8080 / health
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -163,7 +760,9 @@ func main() {
 	
 
 	fmt.Println("Starting server on localhost:" + config.Port)
-	err = http.ListenAndServe(":"+config.Port, nil)
+	// semua route butuh token kecuali health check dan login
+	handler := handlers.RequirePermissions(routePermissions, http.DefaultServeMux)
+	err = http.ListenAndServe(":"+config.Port, handlers.RequireAuth(authService, handler, "/health", "/api/auth/login", "/api/auth/refresh"))
 	if err != nil {
 		fmt.Println("Error starting server")
 	}
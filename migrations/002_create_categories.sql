@@ -0,0 +1,43 @@
+-- Create categories table with optional parent category
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    parent_id INTEGER REFERENCES categories(id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+
+-- Move existing free-text category names into the categories table
+INSERT INTO categories (id, name)
+SELECT DISTINCT ON (category_id) category_id, COALESCE(category_name, 'Category ' || category_id)
+FROM products
+WHERE category_id IS NOT NULL
+ORDER BY category_id, category_name;
+
+-- Names differing only in case become one category, the lowest id is kept
+-- and its products are moved over before the unique index is built
+CREATE TEMPORARY TABLE category_merges AS
+SELECT id, MIN(id) OVER (PARTITION BY COALESCE(parent_id, 0), LOWER(name)) AS keep_id
+FROM categories;
+
+UPDATE products p SET category_id = m.keep_id
+FROM category_merges m
+WHERE p.category_id = m.id AND m.id <> m.keep_id;
+
+DELETE FROM categories c
+USING category_merges m
+WHERE c.id = m.id AND m.id <> m.keep_id;
+
+DROP TABLE category_merges;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_parent_name ON categories(COALESCE(parent_id, 0), LOWER(name));
+
+SELECT setval(pg_get_serial_sequence('categories', 'id'), COALESCE((SELECT MAX(id) FROM categories), 0) + 1, false);
+
+-- Products reference categories by id only, the name is joined in on read
+ALTER TABLE products
+ADD CONSTRAINT fk_products_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE RESTRICT;
+
+ALTER TABLE products DROP COLUMN category_name;
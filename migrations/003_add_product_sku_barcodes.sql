@@ -0,0 +1,15 @@
+-- Add unique SKU to products
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products(sku);
+
+-- One or more barcodes per product (EAN-13, UPC-A or custom)
+CREATE TABLE IF NOT EXISTS product_barcodes (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    code VARCHAR(64) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    CONSTRAINT product_barcodes_code_key UNIQUE (code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_barcodes_product_id ON product_barcodes(product_id);
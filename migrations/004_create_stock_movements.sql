@@ -0,0 +1,34 @@
+-- Append-only ledger of every stock change
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    delta INTEGER NOT NULL,
+    balance INTEGER NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    reference_type VARCHAR(32),
+    reference_id INTEGER,
+    created_by VARCHAR(255),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_id ON stock_movements(product_id, id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_reference ON stock_movements(reference_type, reference_id);
+
+-- Reject UPDATE and DELETE so the ledger stays append-only
+CREATE OR REPLACE FUNCTION stock_movements_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_stock_movements_append_only ON stock_movements;
+CREATE TRIGGER trg_stock_movements_append_only
+BEFORE UPDATE OR DELETE ON stock_movements
+FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
+
+-- Opening balance for existing products
+INSERT INTO stock_movements (product_id, delta, balance, reason, note)
+SELECT id, stock, stock, 'adjustment', 'opening balance'
+FROM products
+WHERE stock <> 0;
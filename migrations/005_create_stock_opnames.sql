@@ -0,0 +1,25 @@
+-- Physical stock count (opname) sessions
+CREATE TABLE IF NOT EXISTS stock_opnames (
+    id SERIAL PRIMARY KEY,
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    category_id INTEGER REFERENCES categories(id),
+    note TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    posted_by VARCHAR(255),
+    posted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_opnames_status ON stock_opnames(status);
+
+-- System stock snapshot per product, counted_qty stays NULL until counted
+CREATE TABLE IF NOT EXISTS stock_opname_items (
+    opname_id INTEGER NOT NULL REFERENCES stock_opnames(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    system_stock INTEGER NOT NULL,
+    unit_price INTEGER NOT NULL,
+    counted_qty INTEGER,
+    counted_by VARCHAR(255),
+    counted_at TIMESTAMP,
+    PRIMARY KEY (opname_id, product_id)
+);
@@ -0,0 +1,64 @@
+-- Outlets (branches) sharing one deployment
+CREATE TABLE IF NOT EXISTS outlets (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Outlet 1 is the default for requests that do not name an outlet
+INSERT INTO outlets (id, name) VALUES (1, 'Main') ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('outlets', 'id'), COALESCE((SELECT MAX(id) FROM outlets), 0) + 1, false);
+
+-- Stock per product per outlet; products.stock stays as the total across outlets
+CREATE TABLE IF NOT EXISTS product_stocks (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    stock INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, outlet_id)
+);
+
+INSERT INTO product_stocks (product_id, outlet_id, stock)
+SELECT id, 1, stock FROM products
+ON CONFLICT DO NOTHING;
+
+-- Existing rows belong to the default outlet
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS outlet_id INTEGER NOT NULL DEFAULT 1 REFERENCES outlets(id);
+ALTER TABLE stock_movements ALTER COLUMN outlet_id DROP DEFAULT;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS outlet_id INTEGER NOT NULL DEFAULT 1 REFERENCES outlets(id);
+ALTER TABLE transactions ALTER COLUMN outlet_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_transactions_outlet_created_at ON transactions(outlet_id, created_at);
+
+ALTER TABLE stock_opnames ADD COLUMN IF NOT EXISTS outlet_id INTEGER NOT NULL DEFAULT 1 REFERENCES outlets(id);
+ALTER TABLE stock_opnames ALTER COLUMN outlet_id DROP DEFAULT;
+
+ALTER TABLE purchase_orders ADD COLUMN IF NOT EXISTS outlet_id INTEGER NOT NULL DEFAULT 1 REFERENCES outlets(id);
+ALTER TABLE purchase_orders ALTER COLUMN outlet_id DROP DEFAULT;
+
+ALTER TABLE supplier_returns ADD COLUMN IF NOT EXISTS outlet_id INTEGER NOT NULL DEFAULT 1 REFERENCES outlets(id);
+ALTER TABLE supplier_returns ALTER COLUMN outlet_id DROP DEFAULT;
+
+-- Transfers between outlets: stock leaves the source on creation and is
+-- in transit until received at the destination
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id SERIAL PRIMARY KEY,
+    from_outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    to_outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    status VARCHAR(16) NOT NULL DEFAULT 'in_transit',
+    note TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_by VARCHAR(255),
+    completed_at TIMESTAMP,
+    CHECK (from_outlet_id <> to_outlet_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_status ON stock_transfers(status);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_items (
+    id SERIAL PRIMARY KEY,
+    stock_transfer_id INTEGER NOT NULL REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0)
+);
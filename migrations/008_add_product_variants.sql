@@ -0,0 +1,10 @@
+-- Variants (size, colour, flavour) are products with a parent. The parent
+-- holds the option axes and carries no stock of its own.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES products(id) ON DELETE CASCADE;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS options JSONB;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS option_values JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_products_parent_id ON products(parent_id);
+
+-- One variant per combination of option values
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_variant_options ON products(parent_id, option_values) WHERE parent_id IS NOT NULL;
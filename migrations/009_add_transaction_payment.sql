@@ -0,0 +1,7 @@
+-- Payment captured at checkout; existing rows are treated as exact cash
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS payment_method VARCHAR(16) NOT NULL DEFAULT 'cash';
+ALTER TABLE transactions ALTER COLUMN payment_method DROP DEFAULT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS amount_tendered INTEGER;
+UPDATE transactions SET amount_tendered = total_amount;
+ALTER TABLE transactions ALTER COLUMN amount_tendered SET NOT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS change_amount INTEGER NOT NULL DEFAULT 0 CHECK (change_amount >= 0);
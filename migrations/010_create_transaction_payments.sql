@@ -0,0 +1,16 @@
+-- One row per tender; only cash rows carry change
+CREATE TABLE IF NOT EXISTS transaction_payments (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    method VARCHAR(16) NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    change_amount INTEGER NOT NULL DEFAULT 0 CHECK (change_amount >= 0 AND change_amount <= amount)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_payments_transaction_id ON transaction_payments(transaction_id);
+
+-- Existing transactions paid with a single method
+INSERT INTO transaction_payments (transaction_id, method, amount, change_amount)
+SELECT t.id, t.payment_method, t.amount_tendered, t.change_amount
+FROM transactions t
+WHERE NOT EXISTS (SELECT 1 FROM transaction_payments p WHERE p.transaction_id = t.id);
@@ -0,0 +1,47 @@
+-- Promotion rules applied automatically at checkout, or with a coupon code
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    scope VARCHAR(16) NOT NULL,
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    category_id INTEGER REFERENCES categories(id) ON DELETE CASCADE,
+    value INTEGER NOT NULL DEFAULT 0,
+    buy_qty INTEGER NOT NULL DEFAULT 0,
+    get_qty INTEGER NOT NULL DEFAULT 0,
+    bundle_qty INTEGER NOT NULL DEFAULT 0,
+    bundle_price INTEGER NOT NULL DEFAULT 0,
+    min_spend INTEGER NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    start_time TIME,
+    end_time TIME,
+    coupon_code VARCHAR(64),
+    usage_limit INTEGER CHECK (usage_limit > 0),
+    usage_count INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promotions_coupon_code ON promotions(coupon_code);
+
+-- Gross is before discounts, total_amount stays the net amount paid
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS gross_amount INTEGER;
+UPDATE transactions SET gross_amount = total_amount;
+ALTER TABLE transactions ALTER COLUMN gross_amount SET NOT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS discount_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS coupon_code VARCHAR(64);
+
+-- subtotal stays quantity * price, discount_amount includes the line's share of cart discounts
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS discount_amount INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS transaction_discounts (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    transaction_detail_id INTEGER REFERENCES transaction_details(id) ON DELETE CASCADE,
+    promotion_id INTEGER NOT NULL REFERENCES promotions(id),
+    name VARCHAR(255) NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_discounts_transaction_id ON transaction_discounts(transaction_id);
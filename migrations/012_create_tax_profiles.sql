@@ -0,0 +1,47 @@
+-- Tax profiles (PPN, PB1, ...) assigned to products or categories
+CREATE TABLE IF NOT EXISTS tax_profiles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    rate NUMERIC(5,2) NOT NULL CHECK (rate >= 0 AND rate <= 100),
+    inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tax_profiles_name ON tax_profiles(LOWER(name));
+
+-- A product without a profile uses its parent's, then its category's (walking up)
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_profile_id INTEGER;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_products_tax_profile;
+ALTER TABLE products
+ADD CONSTRAINT fk_products_tax_profile FOREIGN KEY (tax_profile_id) REFERENCES tax_profiles(id) ON DELETE RESTRICT;
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS tax_profile_id INTEGER;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS fk_categories_tax_profile;
+ALTER TABLE categories
+ADD CONSTRAINT fk_categories_tax_profile FOREIGN KEY (tax_profile_id) REFERENCES tax_profiles(id) ON DELETE RESTRICT;
+
+-- Service charge and cash rounding are set per outlet
+ALTER TABLE outlets ADD COLUMN IF NOT EXISTS service_charge_rate NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (service_charge_rate >= 0 AND service_charge_rate <= 100);
+ALTER TABLE outlets ADD COLUMN IF NOT EXISTS rounding_unit INTEGER NOT NULL DEFAULT 0 CHECK (rounding_unit >= 0);
+ALTER TABLE outlets ADD COLUMN IF NOT EXISTS rounding_mode VARCHAR(16) NOT NULL DEFAULT 'nearest';
+
+-- subtotal_amount is gross minus discounts as priced, total_amount becomes
+-- subtotal plus exclusive tax, service and rounding
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS subtotal_amount INTEGER;
+UPDATE transactions SET subtotal_amount = gross_amount - discount_amount;
+ALTER TABLE transactions ALTER COLUMN subtotal_amount SET NOT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS service_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS rounding_amount INTEGER NOT NULL DEFAULT 0;
+
+-- The profile is copied onto the line so later changes do not alter history.
+-- taxable_amount is the line net of inclusive tax plus its service charge.
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_profile_id INTEGER REFERENCES tax_profiles(id);
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_name VARCHAR(255);
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_rate NUMERIC(5,2) NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS service_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS taxable_amount INTEGER;
+UPDATE transaction_details SET taxable_amount = subtotal - discount_amount;
+ALTER TABLE transaction_details ALTER COLUMN taxable_amount SET NOT NULL;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_amount INTEGER NOT NULL DEFAULT 0;
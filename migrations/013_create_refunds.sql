@@ -0,0 +1,17 @@
+-- A refund is a negative transaction linked to the original sale
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS refund_of_id INTEGER REFERENCES transactions(id);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS refund_reason TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS created_by VARCHAR(255);
+CREATE INDEX IF NOT EXISTS idx_transactions_refund_of_id ON transactions(refund_of_id);
+
+-- Refund lines point at the sold line, damaged goods are not restocked
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS refunded_detail_id INTEGER REFERENCES transaction_details(id);
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS damaged BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_transaction_details_refunded_detail_id ON transaction_details(refunded_detail_id);
+
+-- Money paid back is stored as a negative payment
+ALTER TABLE transaction_payments DROP CONSTRAINT IF EXISTS transaction_payments_amount_check;
+ALTER TABLE transaction_payments DROP CONSTRAINT IF EXISTS transaction_payments_change_amount_check;
+ALTER TABLE transaction_payments DROP CONSTRAINT IF EXISTS transaction_payments_check;
+ALTER TABLE transaction_payments
+ADD CONSTRAINT transaction_payments_change_amount_check CHECK (change_amount >= 0 AND (change_amount = 0 OR change_amount <= amount));
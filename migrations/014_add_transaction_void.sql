@@ -0,0 +1,4 @@
+-- Voided transactions keep their rows but are left out of every report
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS voided_at TIMESTAMP;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS voided_by VARCHAR(255);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS void_reason TEXT;
@@ -0,0 +1,11 @@
+-- Idempotency-Key of POST /api/checkout with the request fingerprint and
+-- the response that is replayed on a retry
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    fingerprint CHAR(64) NOT NULL,
+    transaction_id INTEGER REFERENCES transactions(id) ON DELETE CASCADE,
+    response TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
@@ -0,0 +1,2 @@
+-- Number of times the receipt was printed, reprints are marked COPY
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS print_count INTEGER NOT NULL DEFAULT 0;
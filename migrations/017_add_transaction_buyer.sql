@@ -0,0 +1,4 @@
+-- Buyer identity for business invoices, all optional
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS buyer_name VARCHAR(255);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS buyer_address TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS buyer_npwp VARCHAR(20);
@@ -0,0 +1,32 @@
+-- Cashier shifts, a cashier has at most one open shift per outlet
+CREATE TABLE IF NOT EXISTS shifts (
+    id SERIAL PRIMARY KEY,
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    cashier VARCHAR(255) NOT NULL,
+    opening_float INTEGER NOT NULL CHECK (opening_float >= 0),
+    note TEXT NOT NULL DEFAULT '',
+    opened_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    -- snapshot taken when the drawer is counted
+    closed_at TIMESTAMP,
+    closed_by VARCHAR(255),
+    transaction_count INTEGER,
+    cash_sales INTEGER,
+    cash_refunds INTEGER,
+    expected_cash INTEGER,
+    counted_cash INTEGER
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shifts_open_cashier ON shifts(outlet_id, cashier) WHERE closed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_shifts_opened_at ON shifts(opened_at);
+
+-- Counted cash per denomination at close
+CREATE TABLE IF NOT EXISTS shift_cash_counts (
+    shift_id INTEGER NOT NULL REFERENCES shifts(id) ON DELETE CASCADE,
+    denomination INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity >= 0),
+    PRIMARY KEY (shift_id, denomination)
+);
+
+-- Sales and refunds rung up during a shift
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS shift_id INTEGER REFERENCES shifts(id);
+CREATE INDEX IF NOT EXISTS idx_transactions_shift_id ON transactions(shift_id);
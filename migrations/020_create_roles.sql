@@ -0,0 +1,59 @@
+-- Roles are named permission sets, users get permissions through their roles
+CREATE TABLE IF NOT EXISTS roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission VARCHAR(50) NOT NULL,
+    PRIMARY KEY (role_id, permission)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id INTEGER NOT NULL REFERENCES roles(id),
+    PRIMARY KEY (user_id, role_id)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('cashier', 'checkout, receipts and their own shift'),
+    ('supervisor', 'refunds, voids, stock, purchasing, shifts and reports'),
+    ('owner', 'everything, including catalog, settings and users')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('cashier', 'transaction:create'),
+    ('cashier', 'shift:write'),
+    ('supervisor', 'transaction:create'),
+    ('supervisor', 'transaction:refund'),
+    ('supervisor', 'transaction:void'),
+    ('supervisor', 'shift:write'),
+    ('supervisor', 'shift:read'),
+    ('supervisor', 'stock:write'),
+    ('supervisor', 'purchasing:write'),
+    ('supervisor', 'report:read'),
+    ('owner', 'product:write'),
+    ('owner', 'promotion:write'),
+    ('owner', 'settings:write'),
+    ('owner', 'stock:write'),
+    ('owner', 'purchasing:write'),
+    ('owner', 'transaction:create'),
+    ('owner', 'transaction:refund'),
+    ('owner', 'transaction:void'),
+    ('owner', 'shift:write'),
+    ('owner', 'shift:read'),
+    ('owner', 'report:read'),
+    ('owner', 'user:manage')
+) AS p(role, permission) ON p.role = r.name
+ON CONFLICT DO NOTHING;
+
+-- users that existed before roles keep full access
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id FROM users u, roles r
+WHERE r.name = 'owner' AND NOT EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = u.id);
@@ -0,0 +1,16 @@
+-- Customers and members, found at the till by phone number or member card
+CREATE TABLE IF NOT EXISTS customers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(20),
+    member_card VARCHAR(50),
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_phone ON customers(phone);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_member_card ON customers(member_card);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS customer_id INTEGER REFERENCES customers(id);
+CREATE INDEX IF NOT EXISTS idx_transactions_customer_id ON transactions(customer_id);
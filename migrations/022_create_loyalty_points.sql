@@ -0,0 +1,23 @@
+-- Loyalty points ledger, the balance of a customer is the sum of their entries.
+-- Credits carry an expiry, expired points are booked as expire entries taking
+-- the oldest credits first.
+CREATE TABLE IF NOT EXISTS loyalty_points (
+    id SERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id),
+    transaction_id INTEGER REFERENCES transactions(id),
+    type VARCHAR(20) NOT NULL,
+    points INTEGER NOT NULL CHECK (points <> 0),
+    expires_at TIMESTAMP,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_loyalty_points_customer_id ON loyalty_points(customer_id);
+CREATE INDEX IF NOT EXISTS idx_loyalty_points_transaction_id ON loyalty_points(transaction_id);
+
+-- Points earned per rupiah are multiplied per category, a category without a
+-- multiplier uses its parent's (walking up), 1 at the top
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS points_multiplier NUMERIC(5,2) CHECK (points_multiplier >= 0);
+
+-- Rupiah discount given for redeemed points, shared over the lines like a cart discount
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS points_discount INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- Stock can never go below zero, whatever code path changes it. NOT VALID
+-- keeps existing rows from blocking the migration, every new write is checked.
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_stock_check;
+ALTER TABLE products ADD CONSTRAINT products_stock_check CHECK (stock >= 0) NOT VALID;
+
+ALTER TABLE product_stocks DROP CONSTRAINT IF EXISTS product_stocks_stock_check;
+ALTER TABLE product_stocks ADD CONSTRAINT product_stocks_stock_check CHECK (stock >= 0) NOT VALID;
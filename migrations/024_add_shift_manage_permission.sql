@@ -0,0 +1,5 @@
+-- Closing another cashier's shift needs shift:manage
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, 'shift:manage' FROM roles r
+WHERE r.name IN ('supervisor', 'owner')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,6 @@
+-- Cash handed back for a void leaves the drawer of the shift open at the
+-- time of the void, like a refund
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS void_shift_id INTEGER REFERENCES shifts(id);
+CREATE INDEX IF NOT EXISTS idx_transactions_void_shift_id ON transactions(void_shift_id);
+
+ALTER TABLE shifts ADD COLUMN IF NOT EXISTS cash_voids INTEGER;
@@ -0,0 +1,10 @@
+package models
+
+type Category struct {
+	ID               int      `json:"id"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	ParentID         *int     `json:"parent_id,omitempty"`
+	TaxProfileID     *int     `json:"tax_profile_id,omitempty"`
+	PointsMultiplier *float64 `json:"points_multiplier,omitempty"` // loyalty points multiplier, inherited from the parent when not set
+}
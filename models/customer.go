@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Customer is identified at the till by phone number or member card. Phone
+// numbers are stored as digits starting with 0.
+type Customer struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	Phone      *string   `json:"phone,omitempty"`
+	MemberCard *string   `json:"member_card,omitempty"`
+	Email      string    `json:"email"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CustomerFilter holds the query options for listing customers
+type CustomerFilter struct {
+	Search     string // name, phone or member card
+	Phone      string // exact match
+	MemberCard string // exact match
+	Limit      int
+	Offset     int
+}
+
+// CustomerHistory is the purchase history of a customer. Lifetime spend is
+// net of refunds, voided sales are left out.
+type CustomerHistory struct {
+	Customer      Customer                  `json:"customer"`
+	LifetimeSpend int                       `json:"lifetime_spend"`
+	VisitCount    int                       `json:"visit_count"`
+	LastVisitAt   *time.Time                `json:"last_visit_at,omitempty"`
+	Transactions  *Page[TransactionSummary] `json:"transactions"`
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError is returned when the client sent invalid input
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// NewValidationError creates a ValidationError with a formatted message
+func NewValidationError(format string, args ...any) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// NotFoundError is returned when a referenced record does not exist
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+// NewNotFoundError creates a NotFoundError with a formatted message
+func NewNotFoundError(format string, args ...any) error {
+	return &NotFoundError{Message: fmt.Sprintf(format, args...)}
+}
+
+// ConflictError is returned when a request conflicts with the current state
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// NewConflictError creates a ConflictError with a formatted message
+func NewConflictError(format string, args ...any) error {
+	return &ConflictError{Message: fmt.Sprintf(format, args...)}
+}
+
+// StockShortage describes one checkout line that cannot be fulfilled
+type StockShortage struct {
+	ProductID int `json:"product_id"`
+	Requested int `json:"requested"`
+	Available int `json:"available"`
+}
+
+// InsufficientStockError is returned when one or more lines exceed available stock
+type InsufficientStockError struct {
+	Shortages []StockShortage `json:"shortages"`
+}
+
+func (e *InsufficientStockError) Error() string {
+	parts := make([]string, 0, len(e.Shortages))
+	for _, s := range e.Shortages {
+		parts = append(parts, fmt.Sprintf("product id %d (requested %d, available %d)", s.ProductID, s.Requested, s.Available))
+	}
+	return "insufficient stock: " + strings.Join(parts, ", ")
+}
+
+// UnauthorizedError is returned when credentials or a token are missing or invalid
+type UnauthorizedError struct {
+	Message string
+}
+
+func (e *UnauthorizedError) Error() string {
+	return e.Message
+}
+
+// NewUnauthorizedError creates an UnauthorizedError with a formatted message
+func NewUnauthorizedError(format string, args ...any) error {
+	return &UnauthorizedError{Message: fmt.Sprintf(format, args...)}
+}
+
+// ForbiddenError is returned when the user lacks the permission a request needs
+type ForbiddenError struct {
+	Permission string `json:"permission"`
+}
+
+func (e *ForbiddenError) Error() string {
+	if e.Permission == "" {
+		return "forbidden"
+	}
+	return "missing permission " + e.Permission
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Loyalty points ledger entry types. Reversals are booked when a sale is
+// refunded or voided.
+const (
+	PointsEarn           = "earn"
+	PointsRedeem         = "redeem"
+	PointsExpire         = "expire"
+	PointsEarnReversal   = "earn_reversal"
+	PointsRedeemReversal = "redeem_reversal"
+)
+
+// LoyaltyConfig - customers earn PointsPerUnit points for every SpendUnit
+// rupiah spent on goods after discounts, before tax and service charge.
+// A redeemed point is worth PointValue rupiah.
+type LoyaltyConfig struct {
+	PointsPerUnit int
+	SpendUnit     int           // zero disables earning
+	PointValue    int           // zero disables redeeming
+	Expiry        time.Duration // how long earned points stay valid, zero keeps them forever
+}
+
+// EarnEnabled reports whether checkouts earn points
+func (c LoyaltyConfig) EarnEnabled() bool {
+	return c.PointsPerUnit > 0 && c.SpendUnit > 0
+}
+
+// PointsEntry is one entry in a customer's points ledger. Points are
+// positive for credits and negative for debits.
+type PointsEntry struct {
+	ID            int        `json:"id"`
+	CustomerID    int        `json:"customer_id"`
+	TransactionID *int       `json:"transaction_id,omitempty"`
+	Type          string     `json:"type"`
+	Points        int        `json:"points"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	CreatedBy     *string    `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// PointsAccount is the points balance of a customer with a page of their
+// ledger, newest first. Expired points are already left out of the balance.
+type PointsAccount struct {
+	CustomerID int                `json:"customer_id"`
+	Balance    int                `json:"balance"`
+	Entries    *Page[PointsEntry] `json:"entries"`
+}
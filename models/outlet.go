@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// DefaultOutletID is used when a request does not name an outlet
+const DefaultOutletID = 1
+
+type Outlet struct {
+	ID                int       `json:"id"`
+	Name              string    `json:"name"`
+	Address           string    `json:"address"`
+	ServiceChargeRate float64   `json:"service_charge_rate"` // percentage, 0 for none
+	RoundingUnit      int       `json:"rounding_unit"`       // round the total to a multiple of this, 0 for none
+	RoundingMode      string    `json:"rounding_mode"`       // nearest, up or down
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// OutletStock is the stock of one product at one outlet
+type OutletStock struct {
+	OutletID   int    `json:"outlet_id"`
+	OutletName string `json:"outlet_name"`
+	Stock      int    `json:"stock"`
+}
+
+// Stock transfer statuses
+const (
+	TransferStatusInTransit = "in_transit"
+	TransferStatusReceived  = "received"
+	TransferStatusCancelled = "cancelled"
+)
+
+type StockTransfer struct {
+	ID           int                 `json:"id"`
+	FromOutletID int                 `json:"from_outlet_id"`
+	ToOutletID   int                 `json:"to_outlet_id"`
+	Status       string              `json:"status"`
+	Note         string              `json:"note"`
+	CreatedBy    *string             `json:"created_by,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	CompletedBy  *string             `json:"completed_by,omitempty"`
+	CompletedAt  *time.Time          `json:"completed_at,omitempty"`
+	Items        []StockTransferItem `json:"items"`
+}
+
+type StockTransferItem struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name,omitempty"`
+	Quantity    int    `json:"quantity"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// Page is a single page of a listing with pagination metadata
+type Page[T any] struct {
+	Data       []T  `json:"data"`
+	Total      int  `json:"total"`
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+	NextOffset *int `json:"next_offset"`
+}
+
+// NewPage builds a page, NextOffset is nil on the last page
+func NewPage[T any](data []T, total, limit, offset int) *Page[T] {
+	page := &Page[T]{
+		Data:   data,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	if next := offset + len(data); next < total {
+		page.NextOffset = &next
+	}
+	return page
+}
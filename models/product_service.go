@@ -1,10 +1,84 @@
 package models
 
 type Product struct {
-	ID           int     `json:"id"`
-	Name         string  `json:"name"`
-	Price        int     `json:"price"`
-	Stock        int     `json:"stock"`
-	CategoryID   *int    `json:"category_id,omitempty"`
-	CategoryName *string `json:"category_name,omitempty"`
+	ID           int               `json:"id"`
+	SKU          *string           `json:"sku,omitempty"`
+	Name         string            `json:"name"`
+	Price        int               `json:"price"`
+	Stock        int               `json:"stock"` // total across outlets
+	CategoryID   *int              `json:"category_id,omitempty"`
+	CategoryName *string           `json:"category_name,omitempty"`  // read-only, joined from categories
+	TaxProfileID *int              `json:"tax_profile_id,omitempty"` // falls back to the parent, then the category
+	Barcodes     []Barcode         `json:"barcodes"`
+	Stocks       []OutletStock     `json:"stocks,omitempty"`
+	ParentID     *int              `json:"parent_id,omitempty"`     // read-only, set on variants
+	Options      []ProductOption   `json:"options,omitempty"`       // read-only, option axes of a parent
+	OptionValues map[string]string `json:"option_values,omitempty"` // read-only, axis -> value of a variant
+	Variants     []Product         `json:"variants,omitempty"`
+}
+
+// ProductOption is one variant axis of a parent product, e.g. size or flavour
+type ProductOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// VariantGenerateRequest sets the option axes of a parent product. A variant
+// is created for every combination of values that does not exist yet.
+type VariantGenerateRequest struct {
+	Options []ProductOption `json:"options"`
+	Price   *int            `json:"price,omitempty"` // defaults to the parent price
+}
+
+// Barcode types
+const (
+	BarcodeEAN13  = "ean13"
+	BarcodeUPCA   = "upca"
+	BarcodeCustom = "custom"
+)
+
+type Barcode struct {
+	Code string `json:"code"`
+	Type string `json:"type"`
+}
+
+// ProductFilter holds the query options for listing products
+type ProductFilter struct {
+	Search     string
+	CategoryID *int
+	MinPrice   *int
+	MaxPrice   *int
+	InStock    bool
+	Sort       string
+	Order      string
+	Limit      int
+	Offset     int
+}
+
+// Product import modes
+const (
+	ImportModeCreate = "create"
+	ImportModeUpsert = "upsert"
+)
+
+// ProductImportRow is one parsed CSV row; Columns lists the columns present
+// in the file so upserts leave missing fields unchanged
+type ProductImportRow struct {
+	Line    int
+	Product Product
+	Columns map[string]bool
+}
+
+type ProductImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type ProductImportResult struct {
+	DryRun    bool                 `json:"dry_run"`
+	Mode      string               `json:"mode"`
+	TotalRows int                  `json:"total_rows"`
+	Created   int                  `json:"created"`
+	Updated   int                  `json:"updated"`
+	Errors    []ProductImportError `json:"errors"`
 }
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Promotion types
+const (
+	PromoPercentage = "percentage"  // value is a percentage off
+	PromoFixed      = "fixed"       // value is rupiah off per unit, or per cart for cart scope
+	PromoBuyXGetY   = "buy_x_get_y" // every buy_qty + get_qty units, get_qty are free
+	PromoBundle     = "bundle"      // every bundle_qty units cost bundle_price
+)
+
+// Promotion scopes
+const (
+	PromoScopeItem     = "item"     // one product, including its variants
+	PromoScopeCategory = "category" // products in a category and its subcategories
+	PromoScopeCart     = "cart"     // the whole cart
+)
+
+type Promotion struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	Scope       string     `json:"scope"`
+	ProductID   *int       `json:"product_id,omitempty"`
+	CategoryID  *int       `json:"category_id,omitempty"`
+	Value       int        `json:"value"`
+	BuyQty      int        `json:"buy_qty,omitempty"`
+	GetQty      int        `json:"get_qty,omitempty"`
+	BundleQty   int        `json:"bundle_qty,omitempty"`
+	BundlePrice int        `json:"bundle_price,omitempty"`
+	MinSpend    int        `json:"min_spend"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+	StartTime   *string    `json:"start_time,omitempty"` // daily window HH:MM, may wrap past midnight
+	EndTime     *string    `json:"end_time,omitempty"`
+	CouponCode  *string    `json:"coupon_code,omitempty"`
+	UsageLimit  *int       `json:"usage_limit,omitempty"`
+	UsageCount  int        `json:"usage_count"`
+	Active      bool       `json:"active"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// AppliedDiscount is a promotion applied at checkout. DetailID is set for
+// discounts on a single line and nil for cart discounts.
+type AppliedDiscount struct {
+	PromotionID int    `json:"promotion_id"`
+	Name        string `json:"name"`
+	DetailID    *int   `json:"detail_id,omitempty"`
+	Amount      int    `json:"amount"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+type Supplier struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Phone     string    `json:"phone"`
+	Email     string    `json:"email"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Purchase order statuses
+const (
+	POStatusOrdered   = "ordered"
+	POStatusPartial   = "partial"
+	POStatusReceived  = "received"
+	POStatusCancelled = "cancelled"
+)
+
+type PurchaseOrder struct {
+	ID           int                 `json:"id"`
+	SupplierID   int                 `json:"supplier_id"`
+	SupplierName string              `json:"supplier_name"`
+	OutletID     int                 `json:"outlet_id"`
+	Status       string              `json:"status"`
+	ExpectedDate *string             `json:"expected_date,omitempty"`
+	Note         string              `json:"note"`
+	TotalCost    int                 `json:"total_cost"`
+	CreatedBy    *string             `json:"created_by,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	Items        []PurchaseOrderItem `json:"items,omitempty"`
+	Receipts     []GoodsReceipt      `json:"receipts,omitempty"`
+}
+
+type PurchaseOrderItem struct {
+	ID          int    `json:"id"`
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+	UnitCost    int    `json:"unit_cost"`
+	ReceivedQty int    `json:"received_qty"`
+}
+
+// PurchaseOrderFilter holds the query options for listing purchase orders
+type PurchaseOrderFilter struct {
+	SupplierID  *int
+	OutletID    *int
+	Status      string
+	Outstanding bool
+}
+
+type GoodsReceipt struct {
+	ID              int                `json:"id"`
+	PurchaseOrderID int                `json:"purchase_order_id"`
+	Note            string             `json:"note"`
+	CreatedBy       *string            `json:"created_by,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	Items           []GoodsReceiptItem `json:"items"`
+}
+
+type GoodsReceiptItem struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+	UnitCost  int `json:"unit_cost"`
+}
+
+// GoodsReceiptRequest posts a (partial) delivery; unit_cost defaults to the
+// cost on the purchase order
+type GoodsReceiptRequest struct {
+	Note  string                    `json:"note"`
+	Items []GoodsReceiptRequestItem `json:"items"`
+}
+
+type GoodsReceiptRequestItem struct {
+	ProductID int  `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+	UnitCost  *int `json:"unit_cost,omitempty"`
+}
+
+type SupplierReturn struct {
+	ID              int                  `json:"id"`
+	SupplierID      int                  `json:"supplier_id"`
+	OutletID        int                  `json:"outlet_id"`
+	PurchaseOrderID *int                 `json:"purchase_order_id,omitempty"`
+	Note            string               `json:"note"`
+	CreatedBy       *string              `json:"created_by,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+	Items           []SupplierReturnItem `json:"items"`
+}
+
+type SupplierReturnItem struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+	UnitCost  int `json:"unit_cost"`
+}
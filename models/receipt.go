@@ -0,0 +1,38 @@
+package models
+
+import "fmt"
+
+// Receipt formats
+const (
+	ReceiptFormatText   = "text"
+	ReceiptFormatESCPOS = "escpos"
+)
+
+// Receipt paper widths in mm
+const (
+	Paper58mm = 58
+	Paper80mm = 80
+)
+
+// PrintRequest sends a receipt to a configured network printer. Empty fields
+// fall back to the default printer and paper; the cash drawer opens by
+// default when the transaction has a cash payment.
+type PrintRequest struct {
+	Printer    string `json:"printer,omitempty"` // name of a printer in PRINTERS
+	Paper      int    `json:"paper,omitempty"`
+	OpenDrawer *bool  `json:"open_drawer,omitempty"`
+}
+
+// PrinterError is returned when the printer cannot be reached
+type PrinterError struct {
+	Printer string
+	Err     error
+}
+
+func (e *PrinterError) Error() string {
+	return fmt.Sprintf("printer %s: %v", e.Printer, e.Err)
+}
+
+func (e *PrinterError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Permissions checked on the API routes
+const (
+	PermissionProductWrite      = "product:write"   // products, variants and categories
+	PermissionPromotionWrite    = "promotion:write" // promotions and coupons
+	PermissionSettingsWrite     = "settings:write"  // outlets and tax profiles
+	PermissionStockWrite        = "stock:write"     // adjustments, transfers and stock opname
+	PermissionPurchasingWrite   = "purchasing:write"
+	PermissionTransactionCreate = "transaction:create" // checkout and printing receipts
+	PermissionTransactionRefund = "transaction:refund"
+	PermissionTransactionVoid   = "transaction:void"
+	PermissionShiftWrite        = "shift:write"  // open, view and close one's own shift
+	PermissionShiftRead         = "shift:read"   // every cashier's shifts
+	PermissionShiftManage       = "shift:manage" // close other cashiers' shifts
+	PermissionReportRead        = "report:read"  // sales reports and transaction history
+	PermissionUserManage        = "user:manage"  // users and roles
+)
+
+// Permissions lists every permission a role can be given
+var Permissions = []string{
+	PermissionProductWrite, PermissionPromotionWrite, PermissionSettingsWrite, PermissionStockWrite,
+	PermissionPurchasingWrite, PermissionTransactionCreate, PermissionTransactionRefund, PermissionTransactionVoid,
+	PermissionShiftWrite, PermissionShiftRead, PermissionShiftManage, PermissionReportRead, PermissionUserManage,
+}
+
+// RoleOwner is given to the first user
+const RoleOwner = "owner"
+
+type Role struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UserRolesRequest replaces the roles of a user
+type UserRolesRequest struct {
+	Roles []string `json:"roles"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Shift statuses
+const (
+	ShiftStatusOpen   = "open"
+	ShiftStatusClosed = "closed"
+)
+
+// Denominations are the rupiah notes and coins that can be counted when a
+// shift is closed
+var Denominations = []int{100000, 50000, 20000, 10000, 5000, 2000, 1000, 500, 200, 100}
+
+// Shift is a cashier's session at one outlet. While the shift is open the
+// cash figures are computed live, closing stores them.
+type Shift struct {
+	ID               int         `json:"id"`
+	OutletID         int         `json:"outlet_id"`
+	Cashier          string      `json:"cashier"`
+	Status           string      `json:"status"`
+	OpeningFloat     int         `json:"opening_float"`
+	Note             string      `json:"note"`
+	OpenedAt         time.Time   `json:"opened_at"`
+	ClosedAt         *time.Time  `json:"closed_at,omitempty"`
+	ClosedBy         *string     `json:"closed_by,omitempty"`
+	TransactionCount int         `json:"transaction_count"` // sales, refunds and voided sales not included
+	CashSales        int         `json:"cash_sales"`        // cash taken, net of change
+	CashRefunds      int         `json:"cash_refunds"`      // cash paid back
+	CashVoids        int         `json:"cash_voids"`        // cash handed back for sales voided during the shift
+	ExpectedCash     int         `json:"expected_cash"`     // opening float + cash sales - cash refunds - cash voids
+	CountedCash      *int        `json:"counted_cash,omitempty"`
+	OverShort        *int        `json:"over_short,omitempty"` // counted - expected, negative when short
+	CashCounts       []CashCount `json:"cash_counts"`
+}
+
+// CashCount is the number of notes or coins of one denomination
+type CashCount struct {
+	Denomination int `json:"denomination"`
+	Quantity     int `json:"quantity"`
+	Amount       int `json:"amount"`
+}
+
+type OpenShiftRequest struct {
+	OutletID     *int   `json:"outlet_id,omitempty"`
+	OpeningFloat *int   `json:"opening_float"`
+	Note         string `json:"note"`
+}
+
+type CloseShiftRequest struct {
+	CashCounts []CashCount `json:"cash_counts"`
+	Note       string      `json:"note"`
+}
+
+// ShiftFilter holds the query options for listing shifts
+type ShiftFilter struct {
+	OutletID *int
+	Cashier  string
+	Status   string
+	Limit    int
+	Offset   int
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Stock movement reasons
+const (
+	StockReasonSale       = "sale"
+	StockReasonAdjustment = "adjustment"
+	StockReasonRestock    = "restock"
+	StockReasonCorrection = "correction"
+	StockReasonReturnOut  = "supplier_return"
+	StockReasonTransfer   = "transfer"
+	StockReasonRefund     = "refund"
+	StockReasonVoid       = "void"
+)
+
+// Stock movement reference types
+const (
+	StockRefTransaction = "transaction"
+	StockRefOpname      = "stock_opname"
+	StockRefReceipt     = "goods_receipt"
+	StockRefReturnOut   = "supplier_return"
+	StockRefTransfer    = "stock_transfer"
+)
+
+type StockMovement struct {
+	ID            int64     `json:"id"`
+	ProductID     int       `json:"product_id"`
+	OutletID      int       `json:"outlet_id"`
+	Delta         int       `json:"delta"`
+	Balance       int       `json:"balance"`
+	Reason        string    `json:"reason"`
+	ReferenceType *string   `json:"reference_type,omitempty"`
+	ReferenceID   *int      `json:"reference_id,omitempty"`
+	CreatedBy     *string   `json:"created_by,omitempty"`
+	Note          string    `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// StockAdjustmentRequest is a manual stock change on a single product
+type StockAdjustmentRequest struct {
+	OutletID *int   `json:"outlet_id,omitempty"`
+	Delta    int    `json:"delta"`
+	Reason   string `json:"reason"`
+	Note     string `json:"note"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Stock opname statuses
+const (
+	OpnameStatusOpen      = "open"
+	OpnameStatusPosted    = "posted"
+	OpnameStatusCancelled = "cancelled"
+)
+
+// Count modes: set replaces the counted quantity, add accumulates counts
+// from several devices
+const (
+	CountModeSet = "set"
+	CountModeAdd = "add"
+)
+
+type StockOpname struct {
+	ID           int        `json:"id"`
+	Status       string     `json:"status"`
+	OutletID     int        `json:"outlet_id"`
+	CategoryID   *int       `json:"category_id,omitempty"`
+	Note         string     `json:"note"`
+	CreatedBy    *string    `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	PostedBy     *string    `json:"posted_by,omitempty"`
+	PostedAt     *time.Time `json:"posted_at,omitempty"`
+	ItemCount    int        `json:"item_count"`
+	CountedItems int        `json:"counted_items"`
+}
+
+type OpenOpnameRequest struct {
+	OutletID   *int   `json:"outlet_id"`
+	CategoryID *int   `json:"category_id"`
+	Note       string `json:"note"`
+}
+
+type OpnameCountRequest struct {
+	Mode  string            `json:"mode"`
+	Items []OpnameCountItem `json:"items"`
+}
+
+// OpnameCountItem identifies a product by product_id or barcode
+type OpnameCountItem struct {
+	ProductID int    `json:"product_id,omitempty"`
+	Barcode   string `json:"barcode,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
+type OpnameVarianceLine struct {
+	ProductID     int     `json:"product_id"`
+	ProductName   string  `json:"product_name"`
+	CategoryID    *int    `json:"category_id,omitempty"`
+	CategoryName  *string `json:"category_name,omitempty"`
+	SystemStock   int     `json:"system_stock"`
+	CountedQty    int     `json:"counted_qty"`
+	VarianceQty   int     `json:"variance_qty"`
+	UnitPrice     int     `json:"unit_price"`
+	VarianceValue int     `json:"variance_value"`
+}
+
+type OpnameCategoryVariance struct {
+	CategoryID    *int    `json:"category_id"`
+	CategoryName  *string `json:"category_name"`
+	SystemQty     int     `json:"system_qty"`
+	CountedQty    int     `json:"counted_qty"`
+	VarianceQty   int     `json:"variance_qty"`
+	VarianceValue int     `json:"variance_value"`
+}
+
+type OpnameVarianceReport struct {
+	Opname             StockOpname              `json:"opname"`
+	Lines              []OpnameVarianceLine     `json:"lines"`
+	Categories         []OpnameCategoryVariance `json:"categories"`
+	UncountedItems     int                      `json:"uncounted_items"`
+	TotalVarianceQty   int                      `json:"total_variance_qty"`
+	TotalVarianceValue int                      `json:"total_variance_value"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// TaxProfile is a tax such as PPN or PB1. Rate is a percentage; with
+// Inclusive the product price already contains the tax.
+type TaxProfile struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Rate      float64   `json:"rate"`
+	Inclusive bool      `json:"inclusive"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Rounding modes of the transaction total
+const (
+	RoundingNearest = "nearest"
+	RoundingUp      = "up"
+	RoundingDown    = "down"
+)
+
+// TransactionTax is the tax of one profile within a transaction
+type TransactionTax struct {
+	TaxProfileID  int     `json:"tax_profile_id"`
+	Name          string  `json:"name"`
+	Rate          float64 `json:"rate"`
+	Inclusive     bool    `json:"inclusive"`
+	TaxableAmount int     `json:"taxable_amount"`
+	Amount        int     `json:"amount"`
+}
+
+// TaxReportRow is the tax collected for one profile and rate in a month
+type TaxReportRow struct {
+	Period           string  `json:"period"` // YYYY-MM
+	TaxProfileID     int     `json:"tax_profile_id"`
+	Name             string  `json:"name"`
+	Rate             float64 `json:"rate"`
+	Inclusive        bool    `json:"inclusive"`
+	TaxableAmount    int     `json:"taxable_amount"`
+	TaxAmount        int     `json:"tax_amount"`
+	TotalTransaction int     `json:"total_transaction"`
+}
+
+type TaxReport struct {
+	StartDate          string         `json:"start_date"`
+	EndDate            string         `json:"end_date"`
+	Taxes              []TaxReportRow `json:"taxes"`
+	TotalTax           int            `json:"total_tax"`
+	TotalServiceCharge int            `json:"total_service_charge"`
+}
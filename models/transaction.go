@@ -1,36 +1,236 @@
 package models
 
+import "time"
+
+// Payment methods
+const (
+	PaymentCash     = "cash"
+	PaymentDebit    = "debit"
+	PaymentCredit   = "credit"
+	PaymentQRIS     = "qris"
+	PaymentEWallet  = "ewallet"
+	PaymentTransfer = "transfer"
+
+	// PaymentSplit is the payment_method of a transaction paid with more than one method
+	PaymentSplit = "split"
+)
+
+// Transaction statuses, derived from the void and refund columns
+const (
+	TransactionStatusCompleted = "completed"
+	TransactionStatusRefunded  = "refunded" // a sale with at least one refund
+	TransactionStatusVoided    = "voided"
+	TransactionStatusRefund    = "refund" // the refund transaction itself
+)
+
 type Transaction struct {
-	ID          int                 `json:"id"`
-	TotalAmount int                 `json:"total_amount"`
-	Details     []TransactionDetail `json:"details"`
+	ID             int                  `json:"id"`
+	OutletID       int                  `json:"outlet_id"`
+	ShiftID        *int                 `json:"shift_id,omitempty"` // cashier shift the sale was rung up in
+	CustomerID     *int                 `json:"customer_id,omitempty"`
+	Status         string               `json:"status"`
+	RefundOfID     *int                 `json:"refund_of_id,omitempty"` // set on refunds, amounts are negative
+	RefundReason   string               `json:"refund_reason,omitempty"`
+	CreatedBy      *string              `json:"created_by,omitempty"`
+	VoidedAt       *time.Time           `json:"voided_at,omitempty"`
+	VoidedBy       *string              `json:"voided_by,omitempty"`
+	VoidReason     string               `json:"void_reason,omitempty"`
+	GrossAmount    int                  `json:"gross_amount"`
+	DiscountAmount int                  `json:"discount_amount"`
+	SubtotalAmount int                  `json:"subtotal_amount"` // gross minus discounts
+	ServiceAmount  int                  `json:"service_amount"`
+	TaxAmount      int                  `json:"tax_amount"` // inclusive and exclusive tax
+	RoundingAmount int                  `json:"rounding_amount"`
+	TotalAmount    int                  `json:"total_amount"` // amount to pay
+	Taxes          []TransactionTax     `json:"taxes"`
+	CouponCode     *string              `json:"coupon_code,omitempty"`
+	Buyer          *Buyer               `json:"buyer,omitempty"`
+	Discounts      []AppliedDiscount    `json:"discounts"`
+	PointsDiscount int                  `json:"points_discount"` // rupiah off for redeemed points, part of discount_amount
+	PointsRedeemed int                  `json:"points_redeemed"` // net of reversals, negative on refunds
+	PointsEarned   int                  `json:"points_earned"`
+	PointsBalance  *int                 `json:"points_balance,omitempty"` // customer balance after checkout
+	PaymentMethod  string               `json:"payment_method"`
+	AmountTendered int                  `json:"amount_tendered"`
+	Change         int                  `json:"change"`
+	Payments       []TransactionPayment `json:"payments"`
+	Details        []TransactionDetail  `json:"details"`
+	PrintCount     int                  `json:"print_count"`
+	CreatedAt      time.Time            `json:"created_at"`
+}
+
+// TransactionSummary is a transaction header in a listing
+type TransactionSummary struct {
+	ID             int       `json:"id"`
+	OutletID       int       `json:"outlet_id"`
+	CustomerID     *int      `json:"customer_id,omitempty"`
+	Status         string    `json:"status"`
+	RefundOfID     *int      `json:"refund_of_id,omitempty"`
+	GrossAmount    int       `json:"gross_amount"`
+	DiscountAmount int       `json:"discount_amount"`
+	TaxAmount      int       `json:"tax_amount"`
+	TotalAmount    int       `json:"total_amount"`
+	PaymentMethod  string    `json:"payment_method"`
+	ItemCount      int       `json:"item_count"` // total quantity
+	CreatedBy      *string   `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TransactionFilter holds the query options for listing transactions
+type TransactionFilter struct {
+	StartDate  string // YYYY-MM-DD, inclusive
+	EndDate    string
+	MinTotal   *int
+	MaxTotal   *int
+	ProductID  *int // contains the product or one of its variants
+	OutletID   *int
+	ShiftID    *int
+	CustomerID *int
+	Status     string
+	Limit      int
+	Offset     int
+}
+
+// TransactionPayment is one tender of a transaction. Change is only given
+// on cash tenders.
+type TransactionPayment struct {
+	ID     int    `json:"id"`
+	Method string `json:"method"`
+	Amount int    `json:"amount"`
+	Change int    `json:"change"`
 }
 
 type TransactionDetail struct {
-	ID            int    `json:"id"`
-	TransactionID int    `json:"transaction_id"`
-	ProductID     int    `json:"product_id"`
-	ProductName   string `json:"product_name"`
-	Quantity      int    `json:"quantity"`
-	Subtotal      int    `json:"subtotal"`
+	ID            int     `json:"id"`
+	TransactionID int     `json:"transaction_id"`
+	ProductID     int     `json:"product_id"`
+	ProductName   string  `json:"product_name"`
+	Quantity      int     `json:"quantity"`
+	Subtotal      int     `json:"subtotal"` // quantity * price, before discount
+	Discount      int     `json:"discount"` // includes the line's share of cart discounts
+	TaxProfileID  *int    `json:"tax_profile_id,omitempty"`
+	TaxName       *string `json:"tax_name,omitempty"`
+	TaxRate       float64 `json:"tax_rate"`
+	TaxInclusive  bool    `json:"tax_inclusive"`
+	Service       int     `json:"service"`
+	TaxableAmount int     `json:"taxable_amount"` // net of inclusive tax, plus service
+	Tax           int     `json:"tax"`
+	// refund lines only
+	RefundedDetailID *int `json:"refunded_detail_id,omitempty"`
+	Damaged          bool `json:"damaged,omitempty"`
 }
 
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
+	OutletID       *int              `json:"outlet_id,omitempty"`
+	Items          []CheckoutItem    `json:"items"`
+	PaymentMethod  string            `json:"payment_method"`            // single payment, defaults to cash
+	AmountTendered *int              `json:"amount_tendered,omitempty"` // single payment, defaults to the exact total
+	Payments       []CheckoutPayment `json:"payments,omitempty"`        // split tender, replaces payment_method and amount_tendered
+	CouponCode     string            `json:"coupon_code,omitempty"`
+	CustomerID     *int              `json:"customer_id,omitempty"`
+	RedeemPoints   int               `json:"redeem_points,omitempty"` // points of the customer taken off the total
+	Buyer          *Buyer            `json:"buyer,omitempty"`         // printed on the invoice
+}
+
+// Buyer is the business customer named on an invoice. NPWP is stored as
+// digits only.
+type Buyer struct {
+	Name    string `json:"name"`
+	Address string `json:"address,omitempty"`
+	NPWP    string `json:"npwp,omitempty"`
+}
+
+type VoidRequest struct {
+	Reason string `json:"reason"`
 }
 
+// RefundRequest returns lines of a transaction. The money is paid back with
+// PaymentMethod, by default the method of the original sale.
+type RefundRequest struct {
+	Items         []RefundItem `json:"items"`
+	Reason        string       `json:"reason"`
+	PaymentMethod string       `json:"payment_method,omitempty"`
+}
+
+// RefundItem identifies the sold line by detail_id or, alternatively, by
+// product_id. Damaged goods are not put back into stock.
+type RefundItem struct {
+	DetailID  int  `json:"detail_id,omitempty"`
+	ProductID int  `json:"product_id,omitempty"`
+	Quantity  int  `json:"quantity"`
+	Damaged   bool `json:"damaged,omitempty"`
+}
+
+// CheckoutPayment is one tender of a split payment. Amount may only be
+// left out when it is the only payment, it then defaults to the exact total.
+type CheckoutPayment struct {
+	Method string `json:"method"`
+	Amount *int   `json:"amount,omitempty"`
+}
+
+// CheckoutItem identifies a line by product_id or, alternatively, by barcode
 type CheckoutItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	ProductID int    `json:"product_id,omitempty"`
+	Barcode   string `json:"barcode,omitempty"`
+	Quantity  int    `json:"quantity"`
 }
 
+// DailySalesSummary amounts are net of refunds, TotalRefund shows how much
+// was paid back
 type DailySalesSummary struct {
-	TotalTransaction   int                `json:"total_transaction"`
-	TotalRevenue       int                `json:"total_revenue"`
+	TotalTransaction   int                `json:"total_transaction"` // sales only
+	TotalRefund        int                `json:"total_refund"`
+	RefundCount        int                `json:"refund_count"`
+	GrossSales         int                `json:"gross_sales"`
+	TotalDiscount      int                `json:"total_discount"`
+	NetSales           int                `json:"net_sales"` // gross minus discounts
+	TotalServiceCharge int                `json:"total_service_charge"`
+	TotalTax           int                `json:"total_tax"`
+	TotalRounding      int                `json:"total_rounding"`
+	TotalRevenue       int                `json:"total_revenue"` // amount collected
 	MostSellingProduct MostSellingProduct `json:"mostselling_product"`
+	Payments           []PaymentSummary   `json:"payments"`
+}
+
+// PaymentSummary is the revenue taken with one payment method, net of change
+type PaymentSummary struct {
+	Method           string `json:"method"`
+	Amount           int    `json:"amount"`
+	TotalTransaction int    `json:"total_transaction"`
 }
 
 type MostSellingProduct struct {
 	Name     string `json:"name"`
 	Quantity int    `json:"quantity"`
 }
+
+// ProductSales is the quantity and revenue of a product in a period. For a
+// parent product the figures are the sum of its variants.
+type ProductSales struct {
+	ProductID int            `json:"product_id"`
+	Name      string         `json:"name"`
+	Quantity  int            `json:"quantity"`
+	Gross     int            `json:"gross"`
+	Discount  int            `json:"discount"`
+	Revenue   int            `json:"revenue"` // net, gross minus discount
+	Variants  []VariantSales `json:"variants,omitempty"`
+}
+
+type VariantSales struct {
+	ProductID    int               `json:"product_id"`
+	Name         string            `json:"name"`
+	OptionValues map[string]string `json:"option_values,omitempty"`
+	Quantity     int               `json:"quantity"`
+	Gross        int               `json:"gross"`
+	Discount     int               `json:"discount"`
+	Revenue      int               `json:"revenue"`
+}
+
+// IdempotencyKey makes a checkout safe to retry. Replayed is set by the
+// repository when the stored response of an earlier request is returned.
+type IdempotencyKey struct {
+	Key         string
+	Fingerprint string // sha256 of the normalized request
+	Retention   time.Duration
+	Replayed    bool
+}
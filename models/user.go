@@ -0,0 +1,50 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+type User struct {
+	ID           int        `json:"id"`
+	Username     string     `json:"username"`
+	PasswordHash string     `json:"-"`
+	Active       bool       `json:"active"`
+	Locked       bool       `json:"locked"` // too many failed logins, until LockedUntil
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
+	Roles        []string   `json:"roles"`
+	Permissions  []string   `json:"permissions"` // union of the permissions of the roles
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (u *User) HasPermission(permission string) bool {
+	return slices.Contains(u.Permissions, permission)
+}
+
+// UserRequest creates or updates a user. On update an empty password keeps
+// the current one and the username cannot be changed.
+type UserRequest struct {
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	Active   *bool    `json:"active,omitempty"`
+	Roles    []string `json:"roles,omitempty"` // on create only, see UserRolesRequest
+}
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenResponse is returned by login and refresh. ExpiresIn is the lifetime
+// of the access token in seconds.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	User         User   `json:"user"`
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type CategoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
+	query := "SELECT id, name, description, parent_id, tax_profile_id, points_multiplier FROM categories ORDER BY id"
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make([]models.Category, 0)
+	for rows.Next() {
+		var c models.Category
+		err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.ParentID, &c.TaxProfileID, &c.PointsMultiplier)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+// GetByID - get category by ID
+func (repo *CategoryRepository) GetByID(id int) (*models.Category, error) {
+	query := "SELECT id, name, description, parent_id, tax_profile_id, points_multiplier FROM categories WHERE id = $1"
+
+	var c models.Category
+	err := repo.db.QueryRow(query, id).Scan(&c.ID, &c.Name, &c.Description, &c.ParentID, &c.TaxProfileID, &c.PointsMultiplier)
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("category not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (repo *CategoryRepository) Create(category *models.Category) error {
+	query := "INSERT INTO categories (name, description, parent_id, tax_profile_id, points_multiplier) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	err := repo.db.QueryRow(query, category.Name, category.Description, category.ParentID, category.TaxProfileID, category.PointsMultiplier).Scan(&category.ID)
+	return translateCategoryError(err)
+}
+
+func (repo *CategoryRepository) Update(category *models.Category) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// parent tidak boleh category itu sendiri atau salah satu turunannya
+	if category.ParentID != nil {
+		// kunci tabel supaya dua pindah parent bersamaan tidak membuat cycle
+		// yang lolos pengecekan masing-masing
+		if _, err := tx.Exec("LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+
+		var isDescendant bool
+		err := tx.QueryRow(`
+			WITH RECURSIVE descendants AS (
+				SELECT id FROM categories WHERE id = $1
+				UNION ALL
+				SELECT c.id FROM categories c JOIN descendants d ON c.parent_id = d.id
+			)
+			SELECT EXISTS (SELECT 1 FROM descendants WHERE id = $2)
+		`, category.ID, *category.ParentID).Scan(&isDescendant)
+		if err != nil {
+			return err
+		}
+		if isDescendant {
+			return models.NewValidationError("parent_id would create a cycle")
+		}
+	}
+
+	query := "UPDATE categories SET name = $1, description = $2, parent_id = $3, tax_profile_id = $4, points_multiplier = $5 WHERE id = $6"
+	result, err := tx.Exec(query, category.Name, category.Description, category.ParentID, category.TaxProfileID, category.PointsMultiplier, category.ID)
+	if err != nil {
+		return translateCategoryError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return models.NewNotFoundError("category not found")
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a category. Products still in the category are moved to
+// reassignTo, or the delete is refused when reassignTo is nil. Child
+// categories are moved up to the deleted category's parent.
+func (repo *CategoryRepository) Delete(id int, reassignTo *int) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var parentID *int
+	err = tx.QueryRow("SELECT parent_id FROM categories WHERE id = $1 FOR UPDATE", id).Scan(&parentID)
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("category not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	var productCount int
+	err = tx.QueryRow("SELECT COUNT(*) FROM products WHERE category_id = $1", id).Scan(&productCount)
+	if err != nil {
+		return err
+	}
+
+	if productCount > 0 {
+		if reassignTo == nil {
+			return models.NewConflictError("category still has %d products, pass reassign_to to move them", productCount)
+		}
+		if *reassignTo == id {
+			return models.NewValidationError("reassign_to must be a different category")
+		}
+
+		_, err = tx.Exec("UPDATE products SET category_id = $1 WHERE category_id = $2", *reassignTo, id)
+		if isForeignKeyViolation(err) {
+			return models.NewValidationError("reassign_to category %d not found", *reassignTo)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE categories SET parent_id = $1 WHERE parent_id = $2", parentID, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM categories WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// translateCategoryError turns constraint violations into client errors
+func translateCategoryError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == "fk_categories_tax_profile" {
+		return models.NewValidationError("tax profile not found")
+	}
+	if isForeignKeyViolation(err) {
+		return models.NewValidationError("parent category not found")
+	}
+	if isUniqueViolation(err) {
+		return models.NewConflictError("category with the same name already exists under this parent")
+	}
+	return err
+}
@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type CustomerRepository struct {
+	db *sql.DB
+}
+
+func NewCustomerRepository(db *sql.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+const customerColumns = "c.id, c.name, c.phone, c.member_card, c.email, c.note, c.created_at"
+
+func scanCustomer(row rowScanner) (*models.Customer, error) {
+	var c models.Customer
+	err := row.Scan(&c.ID, &c.Name, &c.Phone, &c.MemberCard, &c.Email, &c.Note, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (repo *CustomerRepository) GetAll(filter models.CustomerFilter) ([]models.Customer, int, error) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	addArg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Search != "" {
+		// nomor hp dan kartu member harus sama persis, nama boleh sebagian
+		arg := addArg(filter.Search)
+		conditions = append(conditions, "(c.phone = "+arg+" OR c.member_card = "+arg+
+			" OR c.name ILIKE '%' || "+addArg(escapeLike(filter.Search))+" || '%')")
+	}
+	if filter.Phone != "" {
+		conditions = append(conditions, "c.phone = "+addArg(filter.Phone))
+	}
+	if filter.MemberCard != "" {
+		conditions = append(conditions, "c.member_card = "+addArg(filter.MemberCard))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := repo.db.QueryRow("SELECT COUNT(*) FROM customers c "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := repo.db.Query(
+		"SELECT "+customerColumns+" FROM customers c "+where+" ORDER BY c.name, c.id LIMIT "+addArg(filter.Limit)+" OFFSET "+addArg(filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	customers := make([]models.Customer, 0)
+	for rows.Next() {
+		c, err := scanCustomer(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		customers = append(customers, *c)
+	}
+
+	return customers, total, rows.Err()
+}
+
+// GetByID - get customer by ID
+func (repo *CustomerRepository) GetByID(id int) (*models.Customer, error) {
+	c, err := scanCustomer(repo.db.QueryRow("SELECT "+customerColumns+" FROM customers c WHERE c.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("customer not found")
+	}
+	return c, err
+}
+
+func (repo *CustomerRepository) Create(customer *models.Customer) error {
+	err := repo.db.QueryRow(
+		"INSERT INTO customers (name, phone, member_card, email, note) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		customer.Name, customer.Phone, customer.MemberCard, customer.Email, customer.Note,
+	).Scan(&customer.ID, &customer.CreatedAt)
+	return translateCustomerError(err)
+}
+
+func (repo *CustomerRepository) Update(customer *models.Customer) error {
+	err := repo.db.QueryRow(
+		"UPDATE customers SET name = $1, phone = $2, member_card = $3, email = $4, note = $5 WHERE id = $6 RETURNING created_at",
+		customer.Name, customer.Phone, customer.MemberCard, customer.Email, customer.Note, customer.ID,
+	).Scan(&customer.CreatedAt)
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("customer not found")
+	}
+	return translateCustomerError(err)
+}
+
+func (repo *CustomerRepository) Delete(id int) error {
+	result, err := repo.db.Exec("DELETE FROM customers WHERE id = $1", id)
+	if isForeignKeyViolation(err) {
+		return models.NewConflictError("customer has transactions and cannot be deleted")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return models.NewNotFoundError("customer not found")
+	}
+
+	return nil
+}
+
+// GetStats returns the lifetime spend, number of visits and last visit of a
+// customer. Refunds reduce the spend, voided sales are left out.
+func (repo *CustomerRepository) GetStats(id int) (spend, visits int, lastVisit *time.Time, err error) {
+	err = repo.db.QueryRow(`
+		SELECT COALESCE(SUM(total_amount), 0), COUNT(*) FILTER (WHERE refund_of_id IS NULL), MAX(created_at) FILTER (WHERE refund_of_id IS NULL)
+		FROM transactions
+		WHERE customer_id = $1 AND voided_at IS NULL
+	`, id).Scan(&spend, &visits, &lastVisit)
+	return spend, visits, lastVisit, err
+}
+
+// GetPoints returns the points balance of a customer with a page of their
+// points ledger, newest first, and the number of entries
+func (repo *CustomerRepository) GetPoints(id, limit, offset int) (int, []models.PointsEntry, int, error) {
+	balance, _, err := pointsBalance(repo.db, id)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+
+	var total int
+	err = repo.db.QueryRow("SELECT COUNT(*) FROM loyalty_points WHERE customer_id = $1", id).Scan(&total)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT id, customer_id, transaction_id, type, points, expires_at, created_by, created_at
+		FROM loyalty_points
+		WHERE customer_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, id, limit, offset)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := make([]models.PointsEntry, 0)
+	for rows.Next() {
+		var e models.PointsEntry
+		err := rows.Scan(&e.ID, &e.CustomerID, &e.TransactionID, &e.Type, &e.Points, &e.ExpiresAt, &e.CreatedBy, &e.CreatedAt)
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+
+	return balance, entries, total, rows.Err()
+}
+
+func translateCustomerError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch {
+	case isUniqueViolation(err) && pgErr.ConstraintName == "idx_customers_phone":
+		return models.NewConflictError("phone number already registered to another customer")
+	case isUniqueViolation(err) && pgErr.ConstraintName == "idx_customers_member_card":
+		return models.NewConflictError("member card already registered to another customer")
+	}
+	return err
+}
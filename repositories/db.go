@@ -0,0 +1,11 @@
+package repositories
+
+import "database/sql"
+
+// dbtx is satisfied by both *sql.DB and *sql.Tx so helpers can run inside
+// or outside a transaction
+type dbtx interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
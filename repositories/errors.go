@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"errors"
+	"kasir-api/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// isUniqueViolation reports whether err is a postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isForeignKeyViolation reports whether err is a postgres foreign key violation
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// isCheckViolation reports whether err is a postgres check constraint violation
+func isCheckViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23514"
+}
+
+// isClientError reports whether err was caused by the request data rather
+// than a database failure
+func isClientError(err error) bool {
+	var validationErr *models.ValidationError
+	var notFoundErr *models.NotFoundError
+	var conflictErr *models.ConflictError
+	return errors.As(err, &validationErr) || errors.As(err, &notFoundErr) || errors.As(err, &conflictErr)
+}
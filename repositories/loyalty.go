@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+	"math"
+	"time"
+)
+
+// lockCustomer locks the customer row so concurrent checkouts, refunds and
+// voids of one customer cannot spend or reverse the same points twice
+func lockCustomer(tx *sql.Tx, customerID int) error {
+	err := tx.QueryRow("SELECT id FROM customers WHERE id = $1 FOR UPDATE", customerID).Scan(&customerID)
+	if err == sql.ErrNoRows {
+		return models.NewValidationError("customer %d not found", customerID)
+	}
+	return err
+}
+
+// pointsBalance returns the points balance of a customer and how many of
+// them have expired but are not booked yet. Debits use up the oldest
+// credits first, so what expired is the expired credits not covered by
+// debits. The balance is net of expired points.
+func pointsBalance(db dbtx, customerID int) (balance, expired int, err error) {
+	var total, expiredCredits, debits int
+	err = db.QueryRow(`
+		SELECT COALESCE(SUM(points), 0),
+			COALESCE(SUM(points) FILTER (WHERE points > 0 AND expires_at <= NOW()), 0),
+			COALESCE(-SUM(points) FILTER (WHERE points < 0), 0)
+		FROM loyalty_points
+		WHERE customer_id = $1
+	`, customerID).Scan(&total, &expiredCredits, &debits)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	expired = max(0, expiredCredits-debits)
+	return total - expired, expired, nil
+}
+
+// expirePoints books the expired points of a locked customer and returns
+// the balance that is left
+func expirePoints(tx *sql.Tx, customerID int, createdBy *string) (int, error) {
+	balance, expired, err := pointsBalance(tx, customerID)
+	if err != nil {
+		return 0, err
+	}
+	return balance, postPoints(tx, customerID, nil, models.PointsExpire, -expired, 0, createdBy)
+}
+
+// postPoints adds an entry to the points ledger, nothing is booked for zero
+// points. Credits expire after expiry unless it is zero.
+func postPoints(tx *sql.Tx, customerID int, transactionID *int, entryType string, points int, expiry time.Duration, createdBy *string) error {
+	if points == 0 {
+		return nil
+	}
+
+	var expiresIn *float64
+	if points > 0 && expiry > 0 {
+		seconds := expiry.Seconds()
+		expiresIn = &seconds
+	}
+	_, err := tx.Exec(`
+		INSERT INTO loyalty_points (customer_id, transaction_id, type, points, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5), $6)
+	`, customerID, transactionID, entryType, points, expiresIn, createdBy)
+	return err
+}
+
+// earnedPoints is what a sale earns: every line's net amount, weighted by
+// the points multiplier of its category, counts towards SpendUnit
+func earnedPoints(lines []pricedLine, multipliers map[int]pointsCategory, config models.LoyaltyConfig) int {
+	if !config.EarnEnabled() {
+		return 0
+	}
+
+	spend := 0.0
+	for _, line := range lines {
+		spend += float64(line.gross-line.discount) * resolvePointsMultiplier(line.categoryID, multipliers)
+	}
+	return int(math.Floor(spend)) / config.SpendUnit * config.PointsPerUnit
+}
+
+type pointsCategory struct {
+	parentID   *int
+	multiplier *float64
+}
+
+// resolvePointsMultiplier walks up from the category to the first one with
+// a multiplier, 1 when none has
+func resolvePointsMultiplier(categoryID *int, categories map[int]pointsCategory) float64 {
+	// batasi jumlah langkah supaya data yang berputar tidak membuat loop
+	for steps := 0; categoryID != nil && steps < len(categories); steps++ {
+		c, ok := categories[*categoryID]
+		if !ok {
+			break
+		}
+		if c.multiplier != nil {
+			return *c.multiplier
+		}
+		categoryID = c.parentID
+	}
+	return 1
+}
+
+// loadPointsMultipliers reads the parent and points multiplier of every category
+func loadPointsMultipliers(tx *sql.Tx) (map[int]pointsCategory, error) {
+	rows, err := tx.Query("SELECT id, parent_id, points_multiplier FROM categories")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make(map[int]pointsCategory)
+	for rows.Next() {
+		var id int
+		var c pointsCategory
+		if err := rows.Scan(&id, &c.parentID, &c.multiplier); err != nil {
+			return nil, err
+		}
+		categories[id] = c
+	}
+
+	return categories, rows.Err()
+}
+
+// reverseRefundPoints takes back the points a sale earned and returns the
+// points redeemed on it, in proportion to the gross amount refunded so far.
+// The proportion is cumulative so refunding the whole sale reverses exactly
+// what was booked. The entries are booked on the refund, after the expired
+// points of the locked customer.
+func reverseRefundPoints(tx *sql.Tx, saleID, refundID, customerID int, expiry time.Duration, createdBy *string) error {
+	// poin kadaluarsa dibukukan dulu supaya tidak ikut terpakai oleh pembalikan
+	if _, err := expirePoints(tx, customerID, createdBy); err != nil {
+		return err
+	}
+
+	var saleGross, refundedGross int
+	err := tx.QueryRow(`
+		SELECT t.gross_amount, COALESCE((SELECT -SUM(r.gross_amount) FROM transactions r WHERE r.refund_of_id = t.id), 0)
+		FROM transactions t
+		WHERE t.id = $1
+	`, saleID).Scan(&saleGross, &refundedGross)
+	if err != nil {
+		return err
+	}
+	if saleGross <= 0 {
+		return nil
+	}
+
+	var earned, redeemed, earnReversed, redeemReturned int
+	err = tx.QueryRow(`
+		SELECT
+			COALESCE(SUM(points) FILTER (WHERE transaction_id = $1 AND type = $2), 0),
+			COALESCE(-SUM(points) FILTER (WHERE transaction_id = $1 AND type = $3), 0),
+			COALESCE(-SUM(points) FILTER (WHERE transaction_id <> $1 AND type = $4), 0),
+			COALESCE(SUM(points) FILTER (WHERE transaction_id <> $1 AND type = $5), 0)
+		FROM loyalty_points
+		WHERE transaction_id = $1 OR transaction_id IN (SELECT id FROM transactions WHERE refund_of_id = $1)
+	`, saleID, models.PointsEarn, models.PointsRedeem, models.PointsEarnReversal, models.PointsRedeemReversal).
+		Scan(&earned, &redeemed, &earnReversed, &redeemReturned)
+	if err != nil {
+		return err
+	}
+
+	refunded := min(refundedGross, saleGross)
+	earnBack := earned*refunded/saleGross - earnReversed
+	if err := postPoints(tx, customerID, &refundID, models.PointsEarnReversal, -earnBack, 0, createdBy); err != nil {
+		return err
+	}
+	redeemBack := redeemed*refunded/saleGross - redeemReturned
+	return postPoints(tx, customerID, &refundID, models.PointsRedeemReversal, redeemBack, expiry, createdBy)
+}
+
+// reverseVoidPoints takes back everything a voided sale earned and returns
+// what was redeemed on it, after booking the expired points of the locked
+// customer
+func reverseVoidPoints(tx *sql.Tx, transactionID, customerID int, expiry time.Duration, createdBy *string) error {
+	if _, err := expirePoints(tx, customerID, createdBy); err != nil {
+		return err
+	}
+
+	var earned, redeemed int
+	err := tx.QueryRow(`
+		SELECT COALESCE(SUM(points) FILTER (WHERE type = $2), 0), COALESCE(-SUM(points) FILTER (WHERE type = $3), 0)
+		FROM loyalty_points
+		WHERE transaction_id = $1
+	`, transactionID, models.PointsEarn, models.PointsRedeem).Scan(&earned, &redeemed)
+	if err != nil {
+		return err
+	}
+
+	if err := postPoints(tx, customerID, &transactionID, models.PointsEarnReversal, -earned, 0, createdBy); err != nil {
+		return err
+	}
+	return postPoints(tx, customerID, &transactionID, models.PointsRedeemReversal, redeemed, expiry, createdBy)
+}
+
+// loadTransactionPoints fills in the points a transaction earned and
+// redeemed, net of reversals booked on it
+func loadTransactionPoints(db dbtx, t *models.Transaction) error {
+	return db.QueryRow(`
+		SELECT COALESCE(SUM(points) FILTER (WHERE type IN ($2, $3)), 0), COALESCE(-SUM(points) FILTER (WHERE type IN ($4, $5)), 0)
+		FROM loyalty_points
+		WHERE transaction_id = $1
+	`, t.ID, models.PointsEarn, models.PointsEarnReversal, models.PointsRedeem, models.PointsRedeemReversal).Scan(&t.PointsEarned, &t.PointsRedeemed)
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"kasir-api/models"
+	"testing"
+)
+
+func TestEarnedPoints(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	floatPtr := func(v float64) *float64 { return &v }
+
+	// category 5 dobel poin, 6 turunan dari 5, 7 turunan dari 5 dengan 3x,
+	// 8 tidak dapat poin, 9 mengikuti default, 10 satu setengah kali
+	multipliers := map[int]pointsCategory{
+		5:  {multiplier: floatPtr(2)},
+		6:  {parentID: intPtr(5)},
+		7:  {parentID: intPtr(5), multiplier: floatPtr(3)},
+		8:  {multiplier: floatPtr(0)},
+		9:  {},
+		10: {multiplier: floatPtr(1.5)},
+	}
+	config := models.LoyaltyConfig{PointsPerUnit: 1, SpendUnit: 10000}
+	line := func(categoryID *int, gross, discount int) pricedLine {
+		return pricedLine{categoryID: categoryID, gross: gross, discount: discount}
+	}
+
+	tests := []struct {
+		name   string
+		lines  []pricedLine
+		config models.LoyaltyConfig
+		want   int
+	}{
+		{"no lines", nil, config, 0},
+		{"earning disabled by spend unit", []pricedLine{line(nil, 50000, 0)}, models.LoyaltyConfig{PointsPerUnit: 1}, 0},
+		{"earning disabled by points per unit", []pricedLine{line(nil, 50000, 0)}, models.LoyaltyConfig{SpendUnit: 10000}, 0},
+		{"zero amount", []pricedLine{line(nil, 0, 0)}, config, 0},
+		{"just below one unit", []pricedLine{line(nil, 9999, 0)}, config, 0},
+		{"exactly one unit", []pricedLine{line(nil, 10000, 0)}, config, 1},
+		{"partial units are dropped", []pricedLine{line(nil, 19999, 0)}, config, 1},
+		{"discounts do not earn", []pricedLine{line(nil, 25000, 6000)}, config, 1},
+		{"fully discounted", []pricedLine{line(nil, 25000, 25000)}, config, 0},
+		{"points per unit", []pricedLine{line(nil, 35000, 0)}, models.LoyaltyConfig{PointsPerUnit: 5, SpendUnit: 10000}, 15},
+		{"lines add up before units are counted", []pricedLine{line(nil, 6000, 0), line(nil, 4000, 0)}, config, 1},
+		{"category multiplier", []pricedLine{line(intPtr(5), 10000, 0)}, config, 2},
+		{"multiplier of the parent category", []pricedLine{line(intPtr(6), 10000, 0)}, config, 2},
+		{"nearest multiplier wins", []pricedLine{line(intPtr(7), 10000, 0)}, config, 3},
+		{"category without points", []pricedLine{line(intPtr(8), 100000, 0)}, config, 0},
+		{"category without a multiplier", []pricedLine{line(intPtr(9), 10000, 0)}, config, 1},
+		{"unknown category", []pricedLine{line(intPtr(99), 10000, 0)}, config, 1},
+		{"weighted lines reach a unit", []pricedLine{line(intPtr(5), 4999, 0), line(nil, 2, 0)}, config, 1},
+		{"weighted lines just below a unit", []pricedLine{line(intPtr(5), 4999, 0), line(nil, 1, 0)}, config, 0},
+		{"fractional spend is floored", []pricedLine{line(intPtr(10), 6667, 0)}, config, 1},
+		{"fractional spend below a unit", []pricedLine{line(intPtr(10), 6666, 0)}, config, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := earnedPoints(tt.lines, multipliers, tt.config); got != tt.want {
+				t.Errorf("earnedPoints = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+)
+
+type OutletRepository struct {
+	db *sql.DB
+}
+
+func NewOutletRepository(db *sql.DB) *OutletRepository {
+	return &OutletRepository{db: db}
+}
+
+func (repo *OutletRepository) GetAll() ([]models.Outlet, error) {
+	rows, err := repo.db.Query("SELECT id, name, address, service_charge_rate, rounding_unit, rounding_mode, created_at FROM outlets ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outlets := make([]models.Outlet, 0)
+	for rows.Next() {
+		var o models.Outlet
+		if err := rows.Scan(&o.ID, &o.Name, &o.Address, &o.ServiceChargeRate, &o.RoundingUnit, &o.RoundingMode, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		outlets = append(outlets, o)
+	}
+
+	return outlets, rows.Err()
+}
+
+// GetByID - get outlet by ID
+func (repo *OutletRepository) GetByID(id int) (*models.Outlet, error) {
+	var o models.Outlet
+	err := repo.db.QueryRow("SELECT id, name, address, service_charge_rate, rounding_unit, rounding_mode, created_at FROM outlets WHERE id = $1", id).
+		Scan(&o.ID, &o.Name, &o.Address, &o.ServiceChargeRate, &o.RoundingUnit, &o.RoundingMode, &o.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("outlet not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+func (repo *OutletRepository) Create(outlet *models.Outlet) error {
+	query := `INSERT INTO outlets (name, address, service_charge_rate, rounding_unit, rounding_mode)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	return repo.db.QueryRow(query, outlet.Name, outlet.Address, outlet.ServiceChargeRate, outlet.RoundingUnit, outlet.RoundingMode).
+		Scan(&outlet.ID, &outlet.CreatedAt)
+}
+
+func (repo *OutletRepository) Update(outlet *models.Outlet) error {
+	query := `UPDATE outlets SET name = $1, address = $2, service_charge_rate = $3, rounding_unit = $4, rounding_mode = $5
+		WHERE id = $6 RETURNING created_at`
+	err := repo.db.QueryRow(query, outlet.Name, outlet.Address, outlet.ServiceChargeRate, outlet.RoundingUnit, outlet.RoundingMode, outlet.ID).
+		Scan(&outlet.CreatedAt)
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("outlet not found")
+	}
+	return err
+}
+
+// Delete removes an outlet that has no stock history or sales
+func (repo *OutletRepository) Delete(id int) error {
+	if id == models.DefaultOutletID {
+		return models.NewConflictError("the default outlet cannot be deleted")
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// baris stok kosong tidak dianggap riwayat
+	_, err = tx.Exec("DELETE FROM product_stocks WHERE outlet_id = $1 AND stock = 0", id)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec("DELETE FROM outlets WHERE id = $1", id)
+	if isForeignKeyViolation(err) {
+		return models.NewConflictError("outlet has stock or transactions and cannot be deleted")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return models.NewNotFoundError("outlet not found")
+	}
+
+	return tx.Commit()
+}
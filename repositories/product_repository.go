@@ -2,8 +2,13 @@ package repositories
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"kasir-api/models"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type ProductRepository struct {
@@ -14,71 +19,453 @@ func NewProductRepository(db *sql.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
-func (repo *ProductRepository) GetAll() ([]models.Product, error) {
-	query := "SELECT id, name, price, stock, category_id, category_name FROM products"
-	rows, err := repo.db.Query(query)
+// productSortColumns maps allowed sort fields to their columns
+var productSortColumns = map[string]string{
+	"id":    "p.id",
+	"name":  "p.name",
+	"price": "p.price",
+	"stock": "p.stock",
+}
+
+// GetAll - list products matching the filter, returns the page and total count.
+// A Limit of 0 returns every matching product.
+func (repo *ProductRepository) GetAll(filter models.ProductFilter) ([]models.Product, int, error) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	addArg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Search != "" {
+		conditions = append(conditions, "p.name ILIKE '%' || "+addArg(escapeLike(filter.Search))+" || '%'")
+	}
+	if filter.CategoryID != nil {
+		// termasuk sub-category di bawahnya
+		conditions = append(conditions, `p.category_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM categories WHERE id = `+addArg(*filter.CategoryID)+`
+				UNION ALL
+				SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+			)
+			SELECT id FROM tree
+		)`)
+	}
+	if filter.MinPrice != nil {
+		conditions = append(conditions, "p.price >= "+addArg(*filter.MinPrice))
+	}
+	if filter.MaxPrice != nil {
+		conditions = append(conditions, "p.price <= "+addArg(*filter.MaxPrice))
+	}
+	if filter.InStock {
+		conditions = append(conditions, "p.stock > 0")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := repo.db.QueryRow("SELECT COUNT(*) FROM products p "+where, args...).Scan(&total)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	sortColumn, ok := productSortColumns[filter.Sort]
+	if !ok {
+		sortColumn = "p.id"
+	}
+	direction := "ASC"
+	if filter.Order == "desc" {
+		direction = "DESC"
+	}
+
+	query := `
+		SELECT ` + productColumns + `
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id
+		` + where + `
+		ORDER BY ` + sortColumn + ` ` + direction + `, p.id ` + direction
+	if filter.Limit > 0 {
+		query += ` LIMIT ` + addArg(filter.Limit) + ` OFFSET ` + addArg(filter.Offset)
+	}
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	products := make([]models.Product, 0)
+	page := make([]*models.Product, 0)
 	for rows.Next() {
-		var p models.Product
-		err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID, &p.CategoryName)
+		p, err := scanProduct(rows)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
-		products = append(products, p)
+		page = append(page, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	rows.Close()
+
+	if err := loadBarcodes(repo.db, page); err != nil {
+		return nil, 0, err
+	}
+
+	products := make([]models.Product, 0, len(page))
+	for _, p := range page {
+		products = append(products, *p)
 	}
 
-	return products, nil
+	return products, total, nil
 }
 
-func (repo *ProductRepository) Create(product *models.Product) error {
-	query := "INSERT INTO products (name, price, stock, category_id, category_name) VALUES ($1, $2, $3, $4, $5) RETURNING id"
-	err := repo.db.QueryRow(query, product.Name, product.Price, product.Stock, product.CategoryID, product.CategoryName).Scan(&product.ID)
-	return err
+func (repo *ProductRepository) Create(product *models.Product, outletID int, createdBy *string) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertProduct(tx, product, outletID, createdBy, "initial stock"); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // GetByID - get product by ID
 func (repo *ProductRepository) GetByID(id int) (*models.Product, error) {
-	query := "SELECT id, name, price, stock, category_id, category_name FROM products WHERE id = $1"
+	query := "SELECT " + productColumns + " FROM products p LEFT JOIN categories c ON c.id = p.category_id WHERE p.id = $1"
 
-	var p models.Product
-	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID, &p.CategoryName)
+	p, err := scanProduct(repo.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
-		return nil, errors.New("product not found")
+		return nil, models.NewNotFoundError("product not found")
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &p, nil
+	if err := loadBarcodes(repo.db, []*models.Product{p}); err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT o.id, o.name, ps.stock
+		FROM product_stocks ps
+		JOIN outlets o ON o.id = ps.outlet_id
+		WHERE ps.product_id = $1
+		ORDER BY o.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	p.Stocks = make([]models.OutletStock, 0)
+	for rows.Next() {
+		var stock models.OutletStock
+		if err := rows.Scan(&stock.OutletID, &stock.OutletName, &stock.Stock); err != nil {
+			return nil, err
+		}
+		p.Stocks = append(p.Stocks, stock)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if p.Options != nil {
+		p.Variants, err = loadVariants(repo.db, p.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
 }
 
-func (repo *ProductRepository) Update(product *models.Product) error {
-	query := "UPDATE products SET name = $1, price = $2, stock = $3, category_id = $4, category_name = $5 WHERE id = $6"
-	result, err := repo.db.Exec(query, product.Name, product.Price, product.Stock, product.CategoryID, product.CategoryName, product.ID)
+// GenerateVariants stores the option axes on a parent product and creates a
+// variant for every combination that does not exist yet. Existing variants
+// keep their price, stock and barcodes.
+func (repo *ProductRepository) GenerateVariants(parentID int, options []models.ProductOption, price *int, outletID int, createdBy *string) error {
+	tx, err := repo.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	parent, err := scanProduct(tx.QueryRow("SELECT "+productColumns+" FROM products p LEFT JOIN categories c ON c.id = p.category_id WHERE p.id = $1 FOR UPDATE OF p", parentID))
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("product not found")
+	}
 	if err != nil {
 		return err
 	}
+	if parent.ParentID != nil {
+		return models.NewValidationError("product %d is a variant and cannot have variants", parentID)
+	}
+	if parent.Options == nil && parent.Stock != 0 {
+		return models.NewConflictError("product still has stock, move it to zero before adding variants")
+	}
+	if parent.Options != nil && !sameOptionNames(parent.Options, options) {
+		return models.NewConflictError("option axes of a product with variants cannot be renamed or changed")
+	}
 
-	if rows == 0 {
-		return errors.New("product not found")
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("UPDATE products SET options = $1::jsonb WHERE id = $2", string(encoded), parentID)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	existing, err := loadVariants(tx, parentID)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(existing))
+	for _, v := range existing {
+		seen[variantKey(options, v.OptionValues)] = true
+	}
+
+	if price == nil {
+		price = &parent.Price
+	}
+	for _, values := range optionCombinations(options) {
+		if seen[variantKey(options, values)] {
+			continue
+		}
+
+		labels := make([]string, 0, len(options))
+		for _, option := range options {
+			labels = append(labels, values[option.Name])
+		}
+		variant := &models.Product{
+			Name:       parent.Name + " " + strings.Join(labels, " "),
+			Price:      *price,
+			CategoryID: parent.CategoryID,
+		}
+		if parent.SKU != nil {
+			sku := *parent.SKU + "-" + strings.ToUpper(strings.ReplaceAll(strings.Join(labels, "-"), " ", ""))
+			variant.SKU = &sku
+		}
+		if err := insertProduct(tx, variant, outletID, createdBy, "variant"); err != nil {
+			return err
+		}
+
+		encoded, err := json.Marshal(values)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE products SET parent_id = $1, option_values = $2::jsonb WHERE id = $3", parentID, string(encoded), variant.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetByBarcode - get product by one of its barcodes
+func (repo *ProductRepository) GetByBarcode(code string) (*models.Product, error) {
+	query := `
+		SELECT ` + productColumns + `
+		FROM product_barcodes b
+		JOIN products p ON p.id = b.product_id
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE b.code = $1`
+
+	p, err := scanProduct(repo.db.QueryRow(query, code))
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("no product with barcode %s", code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadBarcodes(repo.db, []*models.Product{p}); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (repo *ProductRepository) Update(product *models.Product, outletID int, createdBy *string) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateProduct(tx, product, outletID, createdBy, "product update"); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Import saves parsed CSV rows in a single transaction. Each row runs under
+// its own savepoint so every failing row is reported; the transaction is
+// only committed when commit is true and no row failed.
+func (repo *ProductRepository) Import(rows []models.ProductImportRow, mode string, commit bool, outletID int, createdBy *string) (int, int, []models.ProductImportError, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	defer tx.Rollback()
+
+	created, updated := 0, 0
+	rowErrors := make([]models.ProductImportError, 0)
+	for _, row := range rows {
+		if _, err := tx.Exec("SAVEPOINT import_row"); err != nil {
+			return 0, 0, nil, err
+		}
+
+		isUpdate, err := importProductRow(tx, row, mode, outletID, createdBy)
+		if err != nil {
+			if _, rbErr := tx.Exec("ROLLBACK TO SAVEPOINT import_row"); rbErr != nil {
+				return 0, 0, nil, rbErr
+			}
+			if !isClientError(err) {
+				return 0, 0, nil, err
+			}
+			rowErrors = append(rowErrors, models.ProductImportError{Line: row.Line, Message: err.Error()})
+			continue
+		}
+
+		if isUpdate {
+			updated++
+		} else {
+			created++
+		}
+	}
+
+	if commit && len(rowErrors) == 0 {
+		if err := tx.Commit(); err != nil {
+			return 0, 0, nil, err
+		}
+	}
+
+	return created, updated, rowErrors, nil
+}
+
+// importProductRow inserts the row, or in upsert mode updates the product
+// matched by SKU (or by name when the row has no SKU)
+func importProductRow(tx *sql.Tx, row models.ProductImportRow, mode string, outletID int, createdBy *string) (bool, error) {
+	product := row.Product
+	if mode != models.ImportModeUpsert {
+		return false, insertProduct(tx, &product, outletID, createdBy, "csv import")
+	}
+
+	var ids []int
+	var rows *sql.Rows
+	var err error
+	if product.SKU != nil {
+		rows, err = tx.Query("SELECT id FROM products WHERE sku = $1 FOR UPDATE", *product.SKU)
+	} else {
+		rows, err = tx.Query("SELECT id FROM products WHERE LOWER(name) = LOWER($1) FOR UPDATE", product.Name)
+	}
+	if err != nil {
+		return false, err
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return false, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	switch len(ids) {
+	case 0:
+		return false, insertProduct(tx, &product, outletID, createdBy, "csv import")
+	case 1:
+	default:
+		return false, models.NewValidationError("name %q matches %d products, add a sku column to disambiguate", product.Name, len(ids))
+	}
+
+	existing, err := scanProduct(tx.QueryRow("SELECT "+productColumns+" FROM products p LEFT JOIN categories c ON c.id = p.category_id WHERE p.id = $1", ids[0]))
+	if err != nil {
+		return false, err
+	}
+	if err := loadBarcodes(tx, []*models.Product{existing}); err != nil {
+		return false, err
+	}
+
+	// kolom yang tidak ada di file tetap pakai nilai lama
+	existing.Name = product.Name
+	existing.Price = product.Price
+	if row.Columns["sku"] {
+		existing.SKU = product.SKU
+	}
+	if row.Columns["stock"] {
+		existing.Stock = product.Stock
+	}
+	if row.Columns["category_id"] {
+		existing.CategoryID = product.CategoryID
+	}
+	if row.Columns["barcodes"] {
+		existing.Barcodes = product.Barcodes
+	}
+
+	return true, updateProduct(tx, existing, outletID, createdBy, "csv import")
+}
+
+// Export calls fn for every product ordered by id while streaming rows from
+// the database
+func (repo *ProductRepository) Export(fn func(models.Product) error) error {
+	query := `
+		SELECT ` + productColumns + `,
+			COALESCE((SELECT string_agg(b.code, '|' ORDER BY b.id) FROM product_barcodes b WHERE b.product_id = p.id), '')
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id
+		ORDER BY p.id`
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := models.Product{Barcodes: make([]models.Barcode, 0)}
+		var codes string
+		var options, optionValues *string
+		err := rows.Scan(&p.ID, &p.SKU, &p.Name, &p.Price, &p.Stock, &p.CategoryID, &p.CategoryName,
+			&p.TaxProfileID, &p.ParentID, &options, &optionValues, &codes)
+		if err != nil {
+			return err
+		}
+		if err := decodeProductOptions(&p, options, optionValues); err != nil {
+			return err
+		}
+		if codes != "" {
+			for _, code := range strings.Split(codes, "|") {
+				p.Barcodes = append(p.Barcodes, models.Barcode{Code: code})
+			}
+		}
+
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
 
 func (repo *ProductRepository) Delete(id int) error {
 	query := "DELETE FROM products WHERE id = $1"
 	result, err := repo.db.Exec(query, id)
+	if isForeignKeyViolation(err) {
+		return models.NewConflictError("product has sales or stock history and cannot be deleted")
+	}
 	if err != nil {
 		return err
 	}
@@ -88,8 +475,276 @@ func (repo *ProductRepository) Delete(id int) error {
 	}
 
 	if rows == 0 {
-		return errors.New("product not found")
+		return models.NewNotFoundError("product not found")
 	}
 
 	return err
 }
+
+// productColumns is the select list read by scanProduct, products aliased
+// as p and categories as c
+const productColumns = "p.id, p.sku, p.name, p.price, p.stock, p.category_id, c.name, p.tax_profile_id, p.parent_id, p.options, p.option_values"
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProduct(row rowScanner) (*models.Product, error) {
+	p := models.Product{Barcodes: make([]models.Barcode, 0)}
+	var options, optionValues *string
+	err := row.Scan(&p.ID, &p.SKU, &p.Name, &p.Price, &p.Stock, &p.CategoryID, &p.CategoryName, &p.TaxProfileID, &p.ParentID, &options, &optionValues)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeProductOptions(&p, options, optionValues); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// loadVariants returns the variants of a parent product with their barcodes
+func loadVariants(db dbtx, parentID int) ([]models.Product, error) {
+	rows, err := db.Query("SELECT "+productColumns+" FROM products p LEFT JOIN categories c ON c.id = p.category_id WHERE p.parent_id = $1 ORDER BY p.id", parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := make([]*models.Product, 0)
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		page = append(page, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := loadBarcodes(db, page); err != nil {
+		return nil, err
+	}
+
+	variants := make([]models.Product, 0, len(page))
+	for _, p := range page {
+		variants = append(variants, *p)
+	}
+	return variants, nil
+}
+
+// optionCombinations returns every combination of option values, in the
+// order of the axes and their values
+func optionCombinations(options []models.ProductOption) []map[string]string {
+	combinations := []map[string]string{{}}
+	for _, option := range options {
+		next := make([]map[string]string, 0, len(combinations)*len(option.Values))
+		for _, combination := range combinations {
+			for _, value := range option.Values {
+				values := make(map[string]string, len(combination)+1)
+				for name, v := range combination {
+					values[name] = v
+				}
+				values[option.Name] = value
+				next = append(next, values)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// variantKey identifies a combination of option values, compared case-insensitively
+func variantKey(options []models.ProductOption, values map[string]string) string {
+	parts := make([]string, 0, len(options))
+	for _, option := range options {
+		parts = append(parts, strings.ToLower(values[option.Name]))
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// sameOptionNames reports whether both sets of axes have the same names in the same order
+func sameOptionNames(a, b []models.ProductOption) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeProductOptions fills Options and OptionValues from their JSONB columns
+func decodeProductOptions(p *models.Product, options, optionValues *string) error {
+	if options != nil {
+		if err := json.Unmarshal([]byte(*options), &p.Options); err != nil {
+			return err
+		}
+	}
+	if optionValues != nil {
+		if err := json.Unmarshal([]byte(*optionValues), &p.OptionValues); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadBarcodes fills the Barcodes field of every product in one query
+func loadBarcodes(db dbtx, products []*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Product, len(products))
+	ids := make([]int, 0, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+		ids = append(ids, p.ID)
+	}
+
+	rows, err := db.Query("SELECT product_id, code, type FROM product_barcodes WHERE product_id = ANY($1::int[]) ORDER BY id", ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID int
+		var b models.Barcode
+		if err := rows.Scan(&productID, &b.Code, &b.Type); err != nil {
+			return err
+		}
+		byID[productID].Barcodes = append(byID[productID].Barcodes, b)
+	}
+
+	return rows.Err()
+}
+
+// insertProduct inserts the product with its barcodes and fills ID and
+// CategoryName. Starting stock is booked at the outlet through the stock ledger.
+func insertProduct(tx *sql.Tx, product *models.Product, outletID int, createdBy *string, note string) error {
+	query := "INSERT INTO products (sku, name, price, stock, category_id, tax_profile_id) VALUES ($1, $2, $3, 0, $4, $5) RETURNING id"
+	err := tx.QueryRow(query, product.SKU, product.Name, product.Price, product.CategoryID, product.TaxProfileID).Scan(&product.ID)
+	if err != nil {
+		return translateProductError(err)
+	}
+
+	if product.Stock != 0 {
+		err := adjustStock(tx, &models.StockMovement{
+			ProductID: product.ID,
+			OutletID:  outletID,
+			Delta:     product.Stock,
+			Reason:    models.StockReasonAdjustment,
+			CreatedBy: createdBy,
+			Note:      note,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := replaceBarcodes(tx, product.ID, product.Barcodes); err != nil {
+		return err
+	}
+
+	return fillCategoryName(tx, product)
+}
+
+// updateProduct overwrites all product fields and barcodes. Stock is the
+// total across outlets, so a changed value is booked as an adjustment of
+// the difference at the given outlet.
+func updateProduct(tx *sql.Tx, product *models.Product, outletID int, createdBy *string, note string) error {
+	var currentStock int
+	err := tx.QueryRow("SELECT stock FROM products WHERE id = $1 FOR UPDATE", product.ID).Scan(&currentStock)
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("product not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	query := "UPDATE products SET sku = $1, name = $2, price = $3, category_id = $4, tax_profile_id = $5 WHERE id = $6"
+	_, err = tx.Exec(query, product.SKU, product.Name, product.Price, product.CategoryID, product.TaxProfileID, product.ID)
+	if err != nil {
+		return translateProductError(err)
+	}
+
+	if delta := product.Stock - currentStock; delta != 0 {
+		if delta < 0 {
+			if err := lockStock(tx, outletID, map[int]int{product.ID: -delta}); err != nil {
+				return err
+			}
+		}
+
+		err := adjustStock(tx, &models.StockMovement{
+			ProductID: product.ID,
+			OutletID:  outletID,
+			Delta:     delta,
+			Reason:    models.StockReasonAdjustment,
+			CreatedBy: createdBy,
+			Note:      note,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := replaceBarcodes(tx, product.ID, product.Barcodes); err != nil {
+		return err
+	}
+
+	return fillCategoryName(tx, product)
+}
+
+func fillCategoryName(tx *sql.Tx, product *models.Product) error {
+	product.CategoryName = nil
+	if product.CategoryID == nil {
+		return nil
+	}
+	return tx.QueryRow("SELECT name FROM categories WHERE id = $1", *product.CategoryID).Scan(&product.CategoryName)
+}
+
+// replaceBarcodes swaps the stored barcodes of a product for the given set
+func replaceBarcodes(tx *sql.Tx, productID int, barcodes []models.Barcode) error {
+	_, err := tx.Exec("DELETE FROM product_barcodes WHERE product_id = $1", productID)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range barcodes {
+		_, err := tx.Exec("INSERT INTO product_barcodes (product_id, code, type) VALUES ($1, $2, $3)", productID, b.Code, b.Type)
+		if err != nil {
+			return translateProductError(err)
+		}
+	}
+
+	return nil
+}
+
+// translateProductError turns constraint violations into client errors
+func translateProductError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch {
+	case isForeignKeyViolation(err) && pgErr.ConstraintName == "fk_products_tax_profile":
+		return models.NewValidationError("tax profile not found")
+	case isForeignKeyViolation(err):
+		return models.NewValidationError("category not found")
+	case isUniqueViolation(err) && pgErr.ConstraintName == "product_barcodes_code_key":
+		return models.NewConflictError("barcode already assigned to another product")
+	case isUniqueViolation(err) && pgErr.ConstraintName == "idx_products_sku":
+		return models.NewConflictError("sku already exists")
+	}
+	return err
+}
+
+// escapeLike escapes LIKE wildcards so user input is matched literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -0,0 +1,179 @@
+package repositories
+
+import (
+	"kasir-api/models"
+	"sort"
+)
+
+// pricedLine is one checkout line while promotions are applied
+type pricedLine struct {
+	productID  int
+	parentID   *int
+	categoryID *int
+	quantity   int
+	price      int
+	gross      int
+	discount   int
+}
+
+// appliedPromotion is a discount given by one promotion, line is the index
+// of the discounted line or -1 for a cart discount
+type appliedPromotion struct {
+	promotion models.Promotion
+	line      int
+	amount    int
+}
+
+// applyPromotions applies the eligible promotions to the lines and returns
+// what was applied. The rules are deterministic:
+//   - every line gets the single best item or category promotion
+//   - the cart then gets the single best cart promotion, computed on the
+//     total after line discounts and shared over the lines pro rata
+//   - ties go to the promotion with the lowest id
+//   - min_spend of line promotions is checked against the gross total,
+//     of cart promotions against the total after line discounts
+//
+// A coupon promotion competes like any other, but a coupon that cannot be
+// applied to the cart at all is an error.
+func applyPromotions(lines []pricedLine, promotions []models.Promotion, categoryParents map[int]*int, couponCode string) ([]appliedPromotion, error) {
+	sort.SliceStable(promotions, func(i, j int) bool { return promotions[i].ID < promotions[j].ID })
+
+	gross := 0
+	for _, line := range lines {
+		gross += line.gross
+	}
+
+	applied := make([]appliedPromotion, 0)
+	couponEligible := false
+	isCoupon := func(p models.Promotion) bool { return p.CouponCode != nil && *p.CouponCode == couponCode }
+
+	for i := range lines {
+		line := &lines[i]
+		var best *models.Promotion
+		bestAmount := 0
+		for j := range promotions {
+			p := &promotions[j]
+			if p.Scope == models.PromoScopeCart || gross < p.MinSpend || !promotionMatches(*p, *line, categoryParents) {
+				continue
+			}
+			amount := lineDiscount(*p, *line)
+			if amount > 0 && isCoupon(*p) {
+				couponEligible = true
+			}
+			if amount > bestAmount {
+				best, bestAmount = p, amount
+			}
+		}
+		if best != nil {
+			line.discount = bestAmount
+			applied = append(applied, appliedPromotion{promotion: *best, line: i, amount: bestAmount})
+		}
+	}
+
+	net := 0
+	for _, line := range lines {
+		net += line.gross - line.discount
+	}
+
+	var best *models.Promotion
+	bestAmount := 0
+	for j := range promotions {
+		p := &promotions[j]
+		if p.Scope != models.PromoScopeCart || net < p.MinSpend {
+			continue
+		}
+		amount := cartDiscount(*p, net)
+		if amount > 0 && isCoupon(*p) {
+			couponEligible = true
+		}
+		if amount > bestAmount {
+			best, bestAmount = p, amount
+		}
+	}
+	if best != nil {
+		shareCartDiscount(lines, net, bestAmount)
+		applied = append(applied, appliedPromotion{promotion: *best, line: -1, amount: bestAmount})
+	}
+
+	if couponCode != "" && !couponEligible {
+		return nil, models.NewValidationError("coupon %s does not apply to this cart", couponCode)
+	}
+
+	return applied, nil
+}
+
+// promotionMatches reports whether an item or category promotion covers the line
+func promotionMatches(p models.Promotion, line pricedLine, categoryParents map[int]*int) bool {
+	switch p.Scope {
+	case models.PromoScopeItem:
+		if p.ProductID == nil {
+			return false
+		}
+		return line.productID == *p.ProductID || (line.parentID != nil && *line.parentID == *p.ProductID)
+	case models.PromoScopeCategory:
+		if p.CategoryID == nil {
+			return false
+		}
+		// naik ke parent category, dibatasi supaya data rusak tidak loop selamanya
+		current := line.categoryID
+		for depth := 0; current != nil && depth <= len(categoryParents); depth++ {
+			if *current == *p.CategoryID {
+				return true
+			}
+			current = categoryParents[*current]
+		}
+	}
+	return false
+}
+
+// lineDiscount is the discount a promotion gives on one line, never more
+// than the line's gross amount
+func lineDiscount(p models.Promotion, line pricedLine) int {
+	amount := 0
+	switch p.Type {
+	case models.PromoPercentage:
+		amount = line.gross * p.Value / 100
+	case models.PromoFixed:
+		amount = p.Value * line.quantity
+	case models.PromoBuyXGetY:
+		if group := p.BuyQty + p.GetQty; group > 0 {
+			amount = line.quantity / group * p.GetQty * line.price
+		}
+	case models.PromoBundle:
+		if p.BundleQty > 0 {
+			amount = line.quantity / p.BundleQty * (p.BundleQty*line.price - p.BundlePrice)
+		}
+	}
+	return max(0, min(amount, line.gross))
+}
+
+// cartDiscount is the discount a cart promotion gives on the net total
+func cartDiscount(p models.Promotion, net int) int {
+	amount := 0
+	switch p.Type {
+	case models.PromoPercentage:
+		amount = net * p.Value / 100
+	case models.PromoFixed:
+		amount = p.Value
+	}
+	return max(0, min(amount, net))
+}
+
+// shareCartDiscount spreads a cart discount over the lines in proportion to
+// their net amount, the rounding remainder goes to the last lines
+func shareCartDiscount(lines []pricedLine, net, amount int) {
+	if net == 0 {
+		return
+	}
+	remaining := amount
+	for i := range lines {
+		share := amount * (lines[i].gross - lines[i].discount) / net
+		lines[i].discount += share
+		remaining -= share
+	}
+	for i := len(lines) - 1; i >= 0 && remaining > 0; i-- {
+		extra := min(remaining, lines[i].gross-lines[i].discount)
+		lines[i].discount += extra
+		remaining -= extra
+	}
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"kasir-api/models"
+	"slices"
+	"testing"
+)
+
+func TestApplyPromotions(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	coupon := "HEMAT"
+
+	// kopi 2 x 10.000 di category 5 (anak dari 4), roti 3 x 5.000 di category 6
+	newLines := func() []pricedLine {
+		return []pricedLine{
+			{productID: 1, categoryID: intPtr(5), quantity: 2, price: 10000, gross: 20000},
+			{productID: 2, categoryID: intPtr(6), quantity: 3, price: 5000, gross: 15000},
+		}
+	}
+	categoryParents := map[int]*int{5: intPtr(4), 4: nil, 6: nil}
+
+	item := func(id, productID int, promoType string, value int) models.Promotion {
+		return models.Promotion{ID: id, Type: promoType, Scope: models.PromoScopeItem, ProductID: intPtr(productID), Value: value}
+	}
+	cart := func(id int, promoType string, value int) models.Promotion {
+		return models.Promotion{ID: id, Type: promoType, Scope: models.PromoScopeCart, Value: value}
+	}
+	withMinSpend := func(p models.Promotion, minSpend int) models.Promotion {
+		p.MinSpend = minSpend
+		return p
+	}
+	withCoupon := func(p models.Promotion) models.Promotion {
+		p.CouponCode = &coupon
+		return p
+	}
+
+	tests := []struct {
+		name          string
+		promotions    []models.Promotion
+		couponCode    string
+		wantDiscounts []int
+		wantApplied   []int // promotion ids in the order applied
+		wantErr       bool
+	}{
+		{
+			name:          "no promotions",
+			wantDiscounts: []int{0, 0},
+			wantApplied:   []int{},
+		},
+		{
+			name:          "best line promotion wins, line promotions do not stack",
+			promotions:    []models.Promotion{item(1, 1, models.PromoPercentage, 10), item(2, 1, models.PromoFixed, 1500)},
+			wantDiscounts: []int{3000, 0},
+			wantApplied:   []int{2},
+		},
+		{
+			name:          "tie goes to the lowest id",
+			promotions:    []models.Promotion{item(7, 1, models.PromoPercentage, 10), item(3, 1, models.PromoPercentage, 10)},
+			wantDiscounts: []int{2000, 0},
+			wantApplied:   []int{3},
+		},
+		{
+			name:          "category promotion covers child categories",
+			promotions:    []models.Promotion{{ID: 1, Type: models.PromoPercentage, Scope: models.PromoScopeCategory, CategoryID: intPtr(4), Value: 20}},
+			wantDiscounts: []int{4000, 0},
+			wantApplied:   []int{1},
+		},
+		{
+			name:          "line and cart promotions stack, cart shared pro rata",
+			promotions:    []models.Promotion{item(1, 1, models.PromoPercentage, 10), cart(2, models.PromoFixed, 3300)},
+			wantDiscounts: []int{3800, 1500},
+			wantApplied:   []int{1, 2},
+		},
+		{
+			name:          "cart share remainder goes to the last line",
+			promotions:    []models.Promotion{cart(1, models.PromoFixed, 5000)},
+			wantDiscounts: []int{2857, 2143},
+			wantApplied:   []int{1},
+		},
+		{
+			name:          "cart min_spend is checked after line discounts",
+			promotions:    []models.Promotion{item(1, 1, models.PromoFixed, 1500), withMinSpend(cart(2, models.PromoPercentage, 10), 33000)},
+			wantDiscounts: []int{3000, 0},
+			wantApplied:   []int{1},
+		},
+		{
+			name:          "line min_spend is checked against the gross total",
+			promotions:    []models.Promotion{withMinSpend(item(1, 1, models.PromoPercentage, 10), 35000)},
+			wantDiscounts: []int{2000, 0},
+			wantApplied:   []int{1},
+		},
+		{
+			name:          "line min_spend just above the gross total",
+			promotions:    []models.Promotion{withMinSpend(item(1, 1, models.PromoPercentage, 10), 35001)},
+			wantDiscounts: []int{0, 0},
+			wantApplied:   []int{},
+		},
+		{
+			name:          "buy 2 get 1",
+			promotions:    []models.Promotion{{ID: 1, Type: models.PromoBuyXGetY, Scope: models.PromoScopeItem, ProductID: intPtr(2), BuyQty: 2, GetQty: 1}},
+			wantDiscounts: []int{0, 5000},
+			wantApplied:   []int{1},
+		},
+		{
+			name:          "bundle price",
+			promotions:    []models.Promotion{{ID: 1, Type: models.PromoBundle, Scope: models.PromoScopeItem, ProductID: intPtr(1), BundleQty: 2, BundlePrice: 18000}},
+			wantDiscounts: []int{2000, 0},
+			wantApplied:   []int{1},
+		},
+		{
+			name:          "discount is capped at the line gross",
+			promotions:    []models.Promotion{item(1, 2, models.PromoFixed, 15000)},
+			wantDiscounts: []int{0, 15000},
+			wantApplied:   []int{1},
+		},
+		{
+			name:          "zero value gives nothing",
+			promotions:    []models.Promotion{item(1, 1, models.PromoPercentage, 0), cart(2, models.PromoFixed, 0)},
+			wantDiscounts: []int{0, 0},
+			wantApplied:   []int{},
+		},
+		{
+			name:          "negative value gives nothing",
+			promotions:    []models.Promotion{item(1, 1, models.PromoFixed, -500), cart(2, models.PromoFixed, -500)},
+			wantDiscounts: []int{0, 0},
+			wantApplied:   []int{},
+		},
+		{
+			name:          "coupon applies",
+			promotions:    []models.Promotion{withCoupon(cart(1, models.PromoFixed, 5000))},
+			couponCode:    coupon,
+			wantDiscounts: []int{2857, 2143},
+			wantApplied:   []int{1},
+		},
+		{
+			name:          "eligible coupon may lose to a better promotion",
+			promotions:    []models.Promotion{withCoupon(cart(1, models.PromoFixed, 1000)), cart(2, models.PromoFixed, 2000)},
+			couponCode:    coupon,
+			wantDiscounts: []int{1142, 858},
+			wantApplied:   []int{2},
+		},
+		{
+			name:       "coupon that does not apply is an error",
+			promotions: []models.Promotion{withCoupon(item(1, 99, models.PromoPercentage, 10))},
+			couponCode: coupon,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := newLines()
+			applied, err := applyPromotions(lines, tt.promotions, categoryParents, tt.couponCode)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("applyPromotions succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			discounts := make([]int, 0, len(lines))
+			for _, line := range lines {
+				discounts = append(discounts, line.discount)
+			}
+			if !slices.Equal(discounts, tt.wantDiscounts) {
+				t.Errorf("line discounts = %v, want %v", discounts, tt.wantDiscounts)
+			}
+
+			ids := make([]int, 0, len(applied))
+			for _, a := range applied {
+				ids = append(ids, a.promotion.ID)
+			}
+			if !slices.Equal(ids, tt.wantApplied) {
+				t.Errorf("applied promotions = %v, want %v", ids, tt.wantApplied)
+			}
+		})
+	}
+}
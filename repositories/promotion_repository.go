@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+)
+
+type PromotionRepository struct {
+	db *sql.DB
+}
+
+func NewPromotionRepository(db *sql.DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+const promotionColumns = `
+	id, name, type, scope, product_id, category_id, value, buy_qty, get_qty, bundle_qty, bundle_price,
+	min_spend, starts_at, ends_at, TO_CHAR(start_time, 'HH24:MI'), TO_CHAR(end_time, 'HH24:MI'),
+	coupon_code, usage_limit, usage_count, active, created_at`
+
+func scanPromotion(row rowScanner) (*models.Promotion, error) {
+	var p models.Promotion
+	err := row.Scan(&p.ID, &p.Name, &p.Type, &p.Scope, &p.ProductID, &p.CategoryID, &p.Value, &p.BuyQty, &p.GetQty, &p.BundleQty, &p.BundlePrice,
+		&p.MinSpend, &p.StartsAt, &p.EndsAt, &p.StartTime, &p.EndTime,
+		&p.CouponCode, &p.UsageLimit, &p.UsageCount, &p.Active, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetAll - list promotions, activeOnly leaves out inactive ones
+func (repo *PromotionRepository) GetAll(activeOnly bool) ([]models.Promotion, error) {
+	rows, err := repo.db.Query("SELECT "+promotionColumns+" FROM promotions WHERE NOT $1 OR active ORDER BY id", activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := make([]models.Promotion, 0)
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, *p)
+	}
+
+	return promotions, rows.Err()
+}
+
+// GetByID - get promotion by ID
+func (repo *PromotionRepository) GetByID(id int) (*models.Promotion, error) {
+	p, err := scanPromotion(repo.db.QueryRow("SELECT "+promotionColumns+" FROM promotions WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("promotion not found")
+	}
+	return p, err
+}
+
+func (repo *PromotionRepository) Create(p *models.Promotion) error {
+	err := repo.db.QueryRow(`
+		INSERT INTO promotions (name, type, scope, product_id, category_id, value, buy_qty, get_qty, bundle_qty, bundle_price,
+			min_spend, starts_at, ends_at, start_time, end_time, coupon_code, usage_limit, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14::time, $15::time, $16, $17, $18)
+		RETURNING id, usage_count, created_at
+	`, p.Name, p.Type, p.Scope, p.ProductID, p.CategoryID, p.Value, p.BuyQty, p.GetQty, p.BundleQty, p.BundlePrice,
+		p.MinSpend, p.StartsAt, p.EndsAt, p.StartTime, p.EndTime, p.CouponCode, p.UsageLimit, p.Active,
+	).Scan(&p.ID, &p.UsageCount, &p.CreatedAt)
+	return translatePromotionError(err)
+}
+
+// Update overwrites the rule, the usage count is kept
+func (repo *PromotionRepository) Update(p *models.Promotion) error {
+	err := repo.db.QueryRow(`
+		UPDATE promotions SET name = $1, type = $2, scope = $3, product_id = $4, category_id = $5, value = $6,
+			buy_qty = $7, get_qty = $8, bundle_qty = $9, bundle_price = $10, min_spend = $11, starts_at = $12, ends_at = $13,
+			start_time = $14::time, end_time = $15::time, coupon_code = $16, usage_limit = $17, active = $18
+		WHERE id = $19
+		RETURNING usage_count, created_at
+	`, p.Name, p.Type, p.Scope, p.ProductID, p.CategoryID, p.Value, p.BuyQty, p.GetQty, p.BundleQty, p.BundlePrice,
+		p.MinSpend, p.StartsAt, p.EndsAt, p.StartTime, p.EndTime, p.CouponCode, p.UsageLimit, p.Active, p.ID,
+	).Scan(&p.UsageCount, &p.CreatedAt)
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("promotion not found")
+	}
+	return translatePromotionError(err)
+}
+
+func (repo *PromotionRepository) Delete(id int) error {
+	result, err := repo.db.Exec("DELETE FROM promotions WHERE id = $1", id)
+	if isForeignKeyViolation(err) {
+		return models.NewConflictError("promotion has been used in transactions, deactivate it instead")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return models.NewNotFoundError("promotion not found")
+	}
+
+	return nil
+}
+
+// translatePromotionError turns constraint violations into client errors
+func translatePromotionError(err error) error {
+	switch {
+	case isForeignKeyViolation(err):
+		return models.NewValidationError("product or category not found")
+	case isUniqueViolation(err):
+		return models.NewConflictError("coupon_code already exists")
+	}
+	return err
+}
+
+// promotionInWindow is true when now is inside the promotion's date range
+// and daily hours. Daily hours may wrap past midnight, e.g. 22:00 - 02:00.
+const promotionInWindow = `
+	(starts_at IS NULL OR starts_at <= NOW())
+	AND (ends_at IS NULL OR ends_at > NOW())
+	AND (start_time IS NULL OR end_time IS NULL
+		OR (start_time <= end_time AND LOCALTIME >= start_time AND LOCALTIME < end_time)
+		OR (start_time > end_time AND (LOCALTIME >= start_time OR LOCALTIME < end_time)))`
+
+// loadActivePromotions returns the promotions that apply right now: every
+// active automatic promotion plus the one with the given coupon code
+func loadActivePromotions(tx *sql.Tx, couponCode string) ([]models.Promotion, error) {
+	if couponCode != "" {
+		var active, valid bool
+		var limit *int
+		var used int
+		err := tx.QueryRow(`
+			SELECT active, `+promotionInWindow+`, usage_limit, usage_count
+			FROM promotions WHERE coupon_code = $1
+		`, couponCode).Scan(&active, &valid, &limit, &used)
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundError("coupon %s not found", couponCode)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !active || !valid {
+			return nil, models.NewValidationError("coupon %s is not valid at this time", couponCode)
+		}
+		if limit != nil && used >= *limit {
+			return nil, models.NewConflictError("coupon %s has reached its usage limit", couponCode)
+		}
+	}
+
+	rows, err := tx.Query(`
+		SELECT `+promotionColumns+`
+		FROM promotions
+		WHERE active AND (coupon_code IS NULL OR coupon_code = $1) AND `+promotionInWindow+`
+		ORDER BY id
+	`, couponCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := make([]models.Promotion, 0)
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, *p)
+	}
+
+	return promotions, rows.Err()
+}
+
+// loadCategoryParents returns the parent of every category, used to match
+// category promotions against subcategories
+func loadCategoryParents(tx *sql.Tx) (map[int]*int, error) {
+	rows, err := tx.Query("SELECT id, parent_id FROM categories")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	parents := make(map[int]*int)
+	for rows.Next() {
+		var id int
+		var parentID *int
+		if err := rows.Scan(&id, &parentID); err != nil {
+			return nil, err
+		}
+		parents[id] = parentID
+	}
+
+	return parents, rows.Err()
+}
@@ -0,0 +1,343 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"sort"
+	"strings"
+)
+
+type PurchaseOrderRepository struct {
+	db *sql.DB
+}
+
+func NewPurchaseOrderRepository(db *sql.DB) *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{db: db}
+}
+
+const purchaseOrderColumns = `
+	po.id, po.supplier_id, s.name, po.outlet_id, po.status, TO_CHAR(po.expected_date, 'YYYY-MM-DD'), po.note,
+	COALESCE((SELECT SUM(i.quantity * i.unit_cost) FROM purchase_order_items i WHERE i.purchase_order_id = po.id), 0),
+	po.created_by, po.created_at`
+
+func scanPurchaseOrder(row rowScanner) (*models.PurchaseOrder, error) {
+	var po models.PurchaseOrder
+	err := row.Scan(&po.ID, &po.SupplierID, &po.SupplierName, &po.OutletID, &po.Status, &po.ExpectedDate, &po.Note, &po.TotalCost, &po.CreatedBy, &po.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &po, nil
+}
+
+func (repo *PurchaseOrderRepository) Create(po *models.PurchaseOrder) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"INSERT INTO purchase_orders (supplier_id, outlet_id, status, expected_date, note, created_by) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		po.SupplierID, po.OutletID, models.POStatusOrdered, po.ExpectedDate, po.Note, po.CreatedBy,
+	).Scan(&po.ID)
+	if isForeignKeyViolation(err) {
+		return models.NewValidationError("supplier or outlet not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, item := range po.Items {
+		_, err := tx.Exec(
+			"INSERT INTO purchase_order_items (purchase_order_id, product_id, quantity, unit_cost) VALUES ($1, $2, $3, $4)",
+			po.ID, item.ProductID, item.Quantity, item.UnitCost,
+		)
+		if isForeignKeyViolation(err) {
+			return models.NewValidationError("product id %d not found", item.ProductID)
+		}
+		if isUniqueViolation(err) {
+			return models.NewValidationError("product id %d is listed more than once", item.ProductID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetAll - list purchase orders filtered by supplier and status, newest first
+func (repo *PurchaseOrderRepository) GetAll(filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	if filter.SupplierID != nil {
+		args = append(args, *filter.SupplierID)
+		conditions = append(conditions, fmt.Sprintf("po.supplier_id = $%d", len(args)))
+	}
+	if filter.OutletID != nil {
+		args = append(args, *filter.OutletID)
+		conditions = append(conditions, fmt.Sprintf("po.outlet_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("po.status = $%d", len(args)))
+	}
+	if filter.Outstanding {
+		conditions = append(conditions, fmt.Sprintf("po.status IN ('%s', '%s')", models.POStatusOrdered, models.POStatusPartial))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT `+purchaseOrderColumns+`
+		FROM purchase_orders po
+		JOIN suppliers s ON s.id = po.supplier_id
+		`+where+`
+		ORDER BY po.id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]models.PurchaseOrder, 0)
+	for rows.Next() {
+		po, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *po)
+	}
+
+	return orders, rows.Err()
+}
+
+// GetByID - get purchase order with its items and receipts
+func (repo *PurchaseOrderRepository) GetByID(id int) (*models.PurchaseOrder, error) {
+	po, err := scanPurchaseOrder(repo.db.QueryRow(`
+		SELECT `+purchaseOrderColumns+`
+		FROM purchase_orders po
+		JOIN suppliers s ON s.id = po.supplier_id
+		WHERE po.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("purchase order not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT i.id, i.product_id, p.name, i.quantity, i.unit_cost, i.received_qty
+		FROM purchase_order_items i
+		JOIN products p ON p.id = i.product_id
+		WHERE i.purchase_order_id = $1
+		ORDER BY i.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	po.Items = make([]models.PurchaseOrderItem, 0)
+	for rows.Next() {
+		var item models.PurchaseOrderItem
+		err := rows.Scan(&item.ID, &item.ProductID, &item.ProductName, &item.Quantity, &item.UnitCost, &item.ReceivedQty)
+		if err != nil {
+			return nil, err
+		}
+		po.Items = append(po.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	receiptRows, err := repo.db.Query(`
+		SELECT r.id, r.purchase_order_id, r.note, r.created_by, r.created_at, i.product_id, i.quantity, i.unit_cost
+		FROM goods_receipts r
+		JOIN goods_receipt_items i ON i.goods_receipt_id = r.id
+		WHERE r.purchase_order_id = $1
+		ORDER BY r.id, i.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer receiptRows.Close()
+
+	po.Receipts = make([]models.GoodsReceipt, 0)
+	for receiptRows.Next() {
+		var receipt models.GoodsReceipt
+		var item models.GoodsReceiptItem
+		err := receiptRows.Scan(&receipt.ID, &receipt.PurchaseOrderID, &receipt.Note, &receipt.CreatedBy, &receipt.CreatedAt,
+			&item.ProductID, &item.Quantity, &item.UnitCost)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(po.Receipts); n > 0 && po.Receipts[n-1].ID == receipt.ID {
+			po.Receipts[n-1].Items = append(po.Receipts[n-1].Items, item)
+			continue
+		}
+		receipt.Items = []models.GoodsReceiptItem{item}
+		po.Receipts = append(po.Receipts, receipt)
+	}
+
+	return po, receiptRows.Err()
+}
+
+// Receive posts a goods receipt against an open purchase order, increases
+// stock at the order's outlet and moves the order to partial or received
+func (repo *PurchaseOrderRepository) Receive(poID int, req models.GoodsReceiptRequest, createdBy *string) (*models.GoodsReceipt, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	var outletID int
+	err = tx.QueryRow("SELECT status, outlet_id FROM purchase_orders WHERE id = $1 FOR UPDATE", poID).Scan(&status, &outletID)
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("purchase order not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status != models.POStatusOrdered && status != models.POStatusPartial {
+		return nil, models.NewConflictError("purchase order is already %s", status)
+	}
+
+	type orderLine struct {
+		id          int
+		quantity    int
+		unitCost    int
+		receivedQty int
+	}
+	lines := make(map[int]*orderLine)
+	rows, err := tx.Query("SELECT id, product_id, quantity, unit_cost, received_qty FROM purchase_order_items WHERE purchase_order_id = $1", poID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var productID int
+		line := &orderLine{}
+		if err := rows.Scan(&line.id, &productID, &line.quantity, &line.unitCost, &line.receivedQty); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		lines[productID] = line
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	receipt := &models.GoodsReceipt{
+		PurchaseOrderID: poID,
+		Note:            req.Note,
+		CreatedBy:       createdBy,
+		Items:           make([]models.GoodsReceiptItem, 0, len(req.Items)),
+	}
+	for _, item := range req.Items {
+		line, ok := lines[item.ProductID]
+		if !ok {
+			return nil, models.NewValidationError("product id %d is not on this purchase order", item.ProductID)
+		}
+		if line.receivedQty+item.Quantity > line.quantity {
+			return nil, models.NewValidationError("product id %d: receiving %d exceeds outstanding quantity %d", item.ProductID, item.Quantity, line.quantity-line.receivedQty)
+		}
+		line.receivedQty += item.Quantity
+
+		unitCost := line.unitCost
+		if item.UnitCost != nil {
+			unitCost = *item.UnitCost
+		}
+		receipt.Items = append(receipt.Items, models.GoodsReceiptItem{ProductID: item.ProductID, Quantity: item.Quantity, UnitCost: unitCost})
+	}
+
+	err = tx.QueryRow(
+		"INSERT INTO goods_receipts (purchase_order_id, note, created_by) VALUES ($1, $2, $3) RETURNING id, created_at",
+		poID, receipt.Note, createdBy,
+	).Scan(&receipt.ID, &receipt.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// update stok berurutan berdasarkan product id supaya tidak deadlock dengan checkout
+	ordered := make([]models.GoodsReceiptItem, len(receipt.Items))
+	copy(ordered, receipt.Items)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].ProductID < ordered[j].ProductID })
+
+	reference := models.StockRefReceipt
+	for _, item := range ordered {
+		_, err := tx.Exec(
+			"INSERT INTO goods_receipt_items (goods_receipt_id, product_id, quantity, unit_cost) VALUES ($1, $2, $3, $4)",
+			receipt.ID, item.ProductID, item.Quantity, item.UnitCost,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Exec("UPDATE purchase_order_items SET received_qty = received_qty + $1 WHERE id = $2", item.Quantity, lines[item.ProductID].id)
+		if err != nil {
+			return nil, err
+		}
+
+		err = adjustStock(tx, &models.StockMovement{
+			ProductID:     item.ProductID,
+			OutletID:      outletID,
+			Delta:         item.Quantity,
+			Reason:        models.StockReasonRestock,
+			ReferenceType: &reference,
+			ReferenceID:   &receipt.ID,
+			CreatedBy:     createdBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	newStatus := models.POStatusReceived
+	for _, line := range lines {
+		if line.receivedQty < line.quantity {
+			newStatus = models.POStatusPartial
+			break
+		}
+	}
+	_, err = tx.Exec("UPDATE purchase_orders SET status = $1 WHERE id = $2", newStatus, poID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+// Cancel closes an open purchase order, quantities not yet received are no
+// longer expected
+func (repo *PurchaseOrderRepository) Cancel(id int) error {
+	var status string
+	err := repo.db.QueryRow(`
+		UPDATE purchase_orders SET status = $1
+		WHERE id = $2 AND status IN ($3, $4)
+		RETURNING status
+	`, models.POStatusCancelled, id, models.POStatusOrdered, models.POStatusPartial).Scan(&status)
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	err = repo.db.QueryRow("SELECT status FROM purchase_orders WHERE id = $1", id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("purchase order not found")
+	}
+	if err != nil {
+		return err
+	}
+	return models.NewConflictError("purchase order is already %s", status)
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+)
+
+type RoleRepository struct {
+	db *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+const roleColumns = `r.id, r.name, r.description, r.created_at,
+	(SELECT COALESCE(STRING_AGG(rp.permission, ',' ORDER BY rp.permission), '') FROM role_permissions rp WHERE rp.role_id = r.id)`
+
+func scanRole(row rowScanner) (*models.Role, error) {
+	var r models.Role
+	var permissions string
+	if err := row.Scan(&r.ID, &r.Name, &r.Description, &r.CreatedAt, &permissions); err != nil {
+		return nil, err
+	}
+	r.Permissions = splitList(permissions)
+	return &r, nil
+}
+
+func (repo *RoleRepository) GetAll() ([]models.Role, error) {
+	rows, err := repo.db.Query("SELECT " + roleColumns + " FROM roles r ORDER BY r.name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := make([]models.Role, 0)
+	for rows.Next() {
+		r, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *r)
+	}
+
+	return roles, rows.Err()
+}
+
+// GetByID - get role by ID
+func (repo *RoleRepository) GetByID(id int) (*models.Role, error) {
+	r, err := scanRole(repo.db.QueryRow("SELECT "+roleColumns+" FROM roles r WHERE r.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("role not found")
+	}
+	return r, err
+}
+
+func (repo *RoleRepository) Create(role *models.Role) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow("INSERT INTO roles (name, description) VALUES ($1, $2) RETURNING id, created_at", role.Name, role.Description).
+		Scan(&role.ID, &role.CreatedAt)
+	if isUniqueViolation(err) {
+		return models.NewConflictError("role %s already exists", role.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := insertPermissions(tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Update replaces the name, description and permissions of a role
+func (repo *RoleRepository) Update(role *models.Role) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow("UPDATE roles SET name = $1, description = $2 WHERE id = $3 RETURNING created_at", role.Name, role.Description, role.ID).
+		Scan(&role.CreatedAt)
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("role not found")
+	}
+	if isUniqueViolation(err) {
+		return models.NewConflictError("role %s already exists", role.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM role_permissions WHERE role_id = $1", role.ID); err != nil {
+		return err
+	}
+	if err := insertPermissions(tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (repo *RoleRepository) Delete(id int) error {
+	result, err := repo.db.Exec("DELETE FROM roles WHERE id = $1", id)
+	if isForeignKeyViolation(err) {
+		return models.NewConflictError("role is assigned to users and cannot be deleted")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return models.NewNotFoundError("role not found")
+	}
+
+	return nil
+}
+
+func insertPermissions(tx *sql.Tx, roleID int, permissions []string) error {
+	for _, permission := range permissions {
+		if _, err := tx.Exec("INSERT INTO role_permissions (role_id, permission) VALUES ($1, $2)", roleID, permission); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"strings"
+)
+
+type ShiftRepository struct {
+	db *sql.DB
+}
+
+func NewShiftRepository(db *sql.DB) *ShiftRepository {
+	return &ShiftRepository{db: db}
+}
+
+const shiftColumns = `
+	s.id, s.outlet_id, s.cashier, s.opening_float, s.note, s.opened_at, s.closed_at, s.closed_by,
+	COALESCE(s.transaction_count, 0), COALESCE(s.cash_sales, 0), COALESCE(s.cash_refunds, 0), COALESCE(s.cash_voids, 0),
+	COALESCE(s.expected_cash, 0), s.counted_cash`
+
+func scanShift(row rowScanner) (*models.Shift, error) {
+	var s models.Shift
+	err := row.Scan(&s.ID, &s.OutletID, &s.Cashier, &s.OpeningFloat, &s.Note, &s.OpenedAt, &s.ClosedAt, &s.ClosedBy,
+		&s.TransactionCount, &s.CashSales, &s.CashRefunds, &s.CashVoids, &s.ExpectedCash, &s.CountedCash)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Status = models.ShiftStatusOpen
+	if s.ClosedAt != nil {
+		s.Status = models.ShiftStatusClosed
+		if s.CountedCash != nil {
+			overShort := *s.CountedCash - s.ExpectedCash
+			s.OverShort = &overShort
+		}
+	}
+	s.CashCounts = make([]models.CashCount, 0)
+	return &s, nil
+}
+
+// Open starts a shift for shift.Cashier at shift.OutletID
+func (repo *ShiftRepository) Open(shift *models.Shift) error {
+	err := repo.db.QueryRow(
+		"INSERT INTO shifts (outlet_id, cashier, opening_float, note) VALUES ($1, $2, $3, $4) RETURNING id, opened_at",
+		shift.OutletID, shift.Cashier, shift.OpeningFloat, shift.Note,
+	).Scan(&shift.ID, &shift.OpenedAt)
+	if isUniqueViolation(err) {
+		return models.NewConflictError("%s already has an open shift at outlet %d", shift.Cashier, shift.OutletID)
+	}
+	if isForeignKeyViolation(err) {
+		return models.NewValidationError("outlet %d not found", shift.OutletID)
+	}
+	if err != nil {
+		return err
+	}
+
+	shift.Status = models.ShiftStatusOpen
+	shift.ExpectedCash = shift.OpeningFloat
+	shift.CashCounts = make([]models.CashCount, 0)
+	return nil
+}
+
+func (repo *ShiftRepository) GetAll(filter models.ShiftFilter) ([]models.Shift, int, error) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	addArg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.OutletID != nil {
+		conditions = append(conditions, "s.outlet_id = "+addArg(*filter.OutletID))
+	}
+	if filter.Cashier != "" {
+		conditions = append(conditions, "s.cashier = "+addArg(filter.Cashier))
+	}
+	switch filter.Status {
+	case models.ShiftStatusOpen:
+		conditions = append(conditions, "s.closed_at IS NULL")
+	case models.ShiftStatusClosed:
+		conditions = append(conditions, "s.closed_at IS NOT NULL")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := repo.db.QueryRow("SELECT COUNT(*) FROM shifts s "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := repo.db.Query(
+		"SELECT "+shiftColumns+" FROM shifts s "+where+" ORDER BY s.opened_at DESC, s.id DESC LIMIT "+addArg(filter.Limit)+" OFFSET "+addArg(filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	shifts := make([]models.Shift, 0)
+	for rows.Next() {
+		s, err := scanShift(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		shifts = append(shifts, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// angka kas shift yang masih buka dihitung dari transaksi
+	for i := range shifts {
+		if shifts[i].ClosedAt == nil {
+			if err := computeShiftCash(repo.db, &shifts[i]); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+
+	return shifts, total, nil
+}
+
+// GetByID - get a shift with its cash counts
+func (repo *ShiftRepository) GetByID(id int) (*models.Shift, error) {
+	return loadShift(repo.db, "SELECT "+shiftColumns+" FROM shifts s WHERE s.id = $1", id)
+}
+
+// GetOpen returns the open shift of cashier at outletID
+func (repo *ShiftRepository) GetOpen(outletID int, cashier string) (*models.Shift, error) {
+	return loadShift(repo.db, "SELECT "+shiftColumns+" FROM shifts s WHERE s.outlet_id = $1 AND s.cashier = $2 AND s.closed_at IS NULL", outletID, cashier)
+}
+
+// Close counts the drawer of an open shift and stores the expected cash as
+// of now. The shift row is locked so a checkout cannot slip in between.
+// When owner is set only that cashier's shift can be closed.
+func (repo *ShiftRepository) Close(id int, counts []models.CashCount, note string, closedBy, owner *string) (*models.Shift, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	shift, err := scanShift(tx.QueryRow("SELECT "+shiftColumns+" FROM shifts s WHERE s.id = $1 FOR UPDATE", id))
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("shift not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil && shift.Cashier != *owner {
+		return nil, &models.ForbiddenError{Permission: models.PermissionShiftManage}
+	}
+	if shift.ClosedAt != nil {
+		return nil, models.NewConflictError("shift %d is already closed", id)
+	}
+
+	if err := computeShiftCash(tx, shift); err != nil {
+		return nil, err
+	}
+
+	counted := 0
+	for i, c := range counts {
+		counts[i].Amount = c.Denomination * c.Quantity
+		counted += counts[i].Amount
+		_, err := tx.Exec("INSERT INTO shift_cash_counts (shift_id, denomination, quantity) VALUES ($1, $2, $3)", id, c.Denomination, c.Quantity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if note != "" {
+		shift.Note = note
+	}
+	err = tx.QueryRow(`
+		UPDATE shifts
+		SET closed_at = NOW(), closed_by = $1, note = $2,
+			transaction_count = $3, cash_sales = $4, cash_refunds = $5, cash_voids = $6, expected_cash = $7, counted_cash = $8
+		WHERE id = $9
+		RETURNING closed_at
+	`, closedBy, shift.Note, shift.TransactionCount, shift.CashSales, shift.CashRefunds, shift.CashVoids, shift.ExpectedCash, counted, id).Scan(&shift.ClosedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	overShort := counted - shift.ExpectedCash
+	shift.Status = models.ShiftStatusClosed
+	shift.ClosedBy = closedBy
+	shift.CountedCash = &counted
+	shift.OverShort = &overShort
+	shift.CashCounts = counts
+	return shift, nil
+}
+
+func loadShift(db dbtx, query string, args ...any) (*models.Shift, error) {
+	shift, err := scanShift(db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("shift not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if shift.ClosedAt == nil {
+		return shift, computeShiftCash(db, shift)
+	}
+
+	rows, err := db.Query("SELECT denomination, quantity FROM shift_cash_counts WHERE shift_id = $1 ORDER BY denomination DESC", shift.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.CashCount
+		if err := rows.Scan(&c.Denomination, &c.Quantity); err != nil {
+			return nil, err
+		}
+		c.Amount = c.Denomination * c.Quantity
+		shift.CashCounts = append(shift.CashCounts, c)
+	}
+
+	return shift, rows.Err()
+}
+
+// computeShiftCash sums the cash tenders of the shift's transactions. Like
+// refunds, the cash handed back for a void counts in the shift open when the
+// sale was voided, the sale itself stays in the shift it was rung up in.
+func computeShiftCash(db dbtx, shift *models.Shift) error {
+	err := db.QueryRow(`
+		SELECT
+			COUNT(DISTINCT t.id) FILTER (WHERE t.shift_id = $1 AND t.refund_of_id IS NULL AND t.voided_at IS NULL),
+			COALESCE(SUM(tp.amount - tp.change_amount) FILTER (WHERE tp.method = $2 AND t.shift_id = $1 AND t.refund_of_id IS NULL), 0),
+			COALESCE(-SUM(tp.amount) FILTER (WHERE tp.method = $2 AND t.shift_id = $1 AND t.refund_of_id IS NOT NULL), 0),
+			COALESCE(SUM(tp.amount - tp.change_amount) FILTER (WHERE tp.method = $2 AND t.void_shift_id = $1), 0)
+		FROM transactions t
+		LEFT JOIN transaction_payments tp ON tp.transaction_id = t.id
+		WHERE t.shift_id = $1 OR t.void_shift_id = $1
+	`, shift.ID, models.PaymentCash).Scan(&shift.TransactionCount, &shift.CashSales, &shift.CashRefunds, &shift.CashVoids)
+	if err != nil {
+		return err
+	}
+
+	shift.ExpectedCash = shift.OpeningFloat + shift.CashSales - shift.CashRefunds - shift.CashVoids
+	return nil
+}
+
+// lockOpenShift returns the open shift of cashier at outletID, nil when
+// there is none. The row is locked FOR SHARE so the shift cannot be closed
+// before the transaction that rings up a sale commits.
+func lockOpenShift(tx *sql.Tx, outletID int, cashier *string) (*int, error) {
+	if cashier == nil {
+		return nil, nil
+	}
+
+	var id int
+	err := tx.QueryRow("SELECT id FROM shifts WHERE outlet_id = $1 AND cashier = $2 AND closed_at IS NULL FOR SHARE", outletID, *cashier).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// cashNeedsShift reports whether cash is refused because no shift is open.
+// Only outlets that have ever opened a shift count their drawer per shift,
+// at other outlets cash is taken without one.
+func cashNeedsShift(tx *sql.Tx, outletID int, shiftID *int) (bool, error) {
+	if shiftID != nil {
+		return false, nil
+	}
+
+	var usesShifts bool
+	err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM shifts WHERE outlet_id = $1)", outletID).Scan(&usesShifts)
+	return usesShifts, err
+}
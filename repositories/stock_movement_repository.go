@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+	"sort"
+)
+
+type StockMovementRepository struct {
+	db *sql.DB
+}
+
+func NewStockMovementRepository(db *sql.DB) *StockMovementRepository {
+	return &StockMovementRepository{db: db}
+}
+
+// GetByProduct - list stock movements of a product, newest first,
+// optionally only those at one outlet
+func (repo *StockMovementRepository) GetByProduct(productID int, outletID *int, limit, offset int) ([]models.StockMovement, int, error) {
+	var exists bool
+	err := repo.db.QueryRow("SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", productID).Scan(&exists)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !exists {
+		return nil, 0, models.NewNotFoundError("product not found")
+	}
+
+	var total int
+	err = repo.db.QueryRow("SELECT COUNT(*) FROM stock_movements WHERE product_id = $1 AND ($2::int IS NULL OR outlet_id = $2)", productID, outletID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT id, product_id, outlet_id, delta, balance, reason, reference_type, reference_id, created_by, note, created_at
+		FROM stock_movements
+		WHERE product_id = $1 AND ($2::int IS NULL OR outlet_id = $2)
+		ORDER BY id DESC
+		LIMIT $3 OFFSET $4
+	`, productID, outletID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	movements := make([]models.StockMovement, 0)
+	for rows.Next() {
+		var m models.StockMovement
+		err := rows.Scan(&m.ID, &m.ProductID, &m.OutletID, &m.Delta, &m.Balance, &m.Reason, &m.ReferenceType, &m.ReferenceID, &m.CreatedBy, &m.Note, &m.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		movements = append(movements, m)
+	}
+
+	return movements, total, rows.Err()
+}
+
+// Adjust applies a manual stock change at one outlet and records it in the ledger
+func (repo *StockMovementRepository) Adjust(m *models.StockMovement) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if m.Delta < 0 {
+		if err := lockStock(tx, m.OutletID, map[int]int{m.ProductID: -m.Delta}); err != nil {
+			return err
+		}
+	}
+
+	if err := adjustStock(tx, m); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockStock locks the given products in ascending id order, the same order
+// checkout uses, and fails with InsufficientStockError listing every product
+// whose requested quantity exceeds its stock at the outlet
+func lockStock(tx *sql.Tx, outletID int, requested map[int]int) error {
+	productIDs := make([]int, 0, len(requested))
+	for productID := range requested {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Ints(productIDs)
+
+	shortages := make([]models.StockShortage, 0)
+	for _, productID := range productIDs {
+		stock, err := lockOutletStock(tx, productID, outletID)
+		if err != nil {
+			return err
+		}
+		if requested[productID] > stock {
+			shortages = append(shortages, models.StockShortage{
+				ProductID: productID,
+				Requested: requested[productID],
+				Available: stock,
+			})
+		}
+	}
+
+	if len(shortages) > 0 {
+		return &models.InsufficientStockError{Shortages: shortages}
+	}
+	return nil
+}
+
+// lockOutletStock locks the product row and returns its stock at the outlet.
+// The products row is always locked before product_stocks so concurrent
+// stock changes cannot deadlock.
+func lockOutletStock(tx *sql.Tx, productID, outletID int) (int, error) {
+	err := tx.QueryRow("SELECT id FROM products WHERE id = $1 FOR UPDATE", productID).Scan(&productID)
+	if err == sql.ErrNoRows {
+		return 0, models.NewNotFoundError("product id %d not found", productID)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return lockStockRow(tx, productID, outletID)
+}
+
+// lockStockRow locks the outlet stock row of a product whose row is already
+// locked and returns its stock, the row is created when missing. The stock
+// must be read in its own statement after the product lock: a join in the
+// locking query reads the stock as it was before the wait for the lock.
+func lockStockRow(tx *sql.Tx, productID, outletID int) (int, error) {
+	_, err := tx.Exec(`
+		INSERT INTO product_stocks (product_id, outlet_id) VALUES ($1, $2)
+		ON CONFLICT (product_id, outlet_id) DO NOTHING
+	`, productID, outletID)
+	if isForeignKeyViolation(err) {
+		return 0, models.NewValidationError("outlet %d not found", outletID)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var stock int
+	err = tx.QueryRow("SELECT stock FROM product_stocks WHERE product_id = $1 AND outlet_id = $2 FOR UPDATE", productID, outletID).Scan(&stock)
+	return stock, err
+}
+
+// adjustStock changes a product's stock at m.OutletID by m.Delta, keeps
+// products.stock as the total across outlets and appends the movement to the
+// ledger. Every code path that changes stock must go through here.
+func adjustStock(tx *sql.Tx, m *models.StockMovement) error {
+	// parent product dengan varian tidak punya stok sendiri
+	var isParent bool
+	err := tx.QueryRow("UPDATE products SET stock = stock + $1 WHERE id = $2 RETURNING options IS NOT NULL", m.Delta, m.ProductID).Scan(&isParent)
+	if isCheckViolation(err) {
+		return models.NewConflictError("stock of product id %d cannot go below zero", m.ProductID)
+	}
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("product id %d not found", m.ProductID)
+	}
+	if err != nil {
+		return err
+	}
+	if isParent {
+		return models.NewValidationError("product id %d has variants, stock is kept per variant", m.ProductID)
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO product_stocks (product_id, outlet_id, stock) VALUES ($1, $2, $3)
+		ON CONFLICT (product_id, outlet_id) DO UPDATE SET stock = product_stocks.stock + EXCLUDED.stock
+		RETURNING stock
+	`, m.ProductID, m.OutletID, m.Delta).Scan(&m.Balance)
+	if isCheckViolation(err) {
+		return models.NewConflictError("stock of product id %d at outlet %d cannot go below zero", m.ProductID, m.OutletID)
+	}
+	if isForeignKeyViolation(err) {
+		return models.NewValidationError("outlet %d not found", m.OutletID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.QueryRow(`
+		INSERT INTO stock_movements (product_id, outlet_id, delta, balance, reason, reference_type, reference_id, created_by, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`, m.ProductID, m.OutletID, m.Delta, m.Balance, m.Reason, m.ReferenceType, m.ReferenceID, m.CreatedBy, m.Note).Scan(&m.ID, &m.CreatedAt)
+}
@@ -0,0 +1,344 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+	"sort"
+)
+
+type StockOpnameRepository struct {
+	db *sql.DB
+}
+
+func NewStockOpnameRepository(db *sql.DB) *StockOpnameRepository {
+	return &StockOpnameRepository{db: db}
+}
+
+const opnameColumns = `
+	o.id, o.status, o.outlet_id, o.category_id, o.note, o.created_by, o.created_at, o.posted_by, o.posted_at,
+	(SELECT COUNT(*) FROM stock_opname_items i WHERE i.opname_id = o.id),
+	(SELECT COUNT(*) FROM stock_opname_items i WHERE i.opname_id = o.id AND i.counted_qty IS NOT NULL)`
+
+func scanOpname(row rowScanner) (*models.StockOpname, error) {
+	var o models.StockOpname
+	err := row.Scan(&o.ID, &o.Status, &o.OutletID, &o.CategoryID, &o.Note, &o.CreatedBy, &o.CreatedAt, &o.PostedBy, &o.PostedAt, &o.ItemCount, &o.CountedItems)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// Open creates a count session and snapshots the outlet stock of every
+// product, or only products in the category and its subcategories. Parent
+// products are skipped, their variants are counted instead.
+func (repo *StockOpnameRepository) Open(opname *models.StockOpname) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"INSERT INTO stock_opnames (status, outlet_id, category_id, note, created_by) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		models.OpnameStatusOpen, opname.OutletID, opname.CategoryID, opname.Note, opname.CreatedBy,
+	).Scan(&opname.ID, &opname.CreatedAt)
+	if isForeignKeyViolation(err) {
+		return models.NewValidationError("outlet or category not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO stock_opname_items (opname_id, product_id, system_stock, unit_price)
+		SELECT $1, p.id, COALESCE(ps.stock, 0), p.price
+		FROM products p
+		LEFT JOIN product_stocks ps ON ps.product_id = p.id AND ps.outlet_id = $3
+		WHERE p.options IS NULL AND ($2::int IS NULL OR p.category_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM categories WHERE id = $2::int
+				UNION ALL
+				SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+			)
+			SELECT id FROM tree
+		))
+	`, opname.ID, opname.CategoryID, opname.OutletID)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	opname.Status = models.OpnameStatusOpen
+	opname.ItemCount = int(count)
+	opname.CountedItems = 0
+
+	return tx.Commit()
+}
+
+func (repo *StockOpnameRepository) GetAll(status string) ([]models.StockOpname, error) {
+	rows, err := repo.db.Query(`
+		SELECT `+opnameColumns+`
+		FROM stock_opnames o
+		WHERE $1 = '' OR o.status = $1
+		ORDER BY o.id DESC
+	`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	opnames := make([]models.StockOpname, 0)
+	for rows.Next() {
+		o, err := scanOpname(rows)
+		if err != nil {
+			return nil, err
+		}
+		opnames = append(opnames, *o)
+	}
+
+	return opnames, rows.Err()
+}
+
+// GetByID - get count session by ID
+func (repo *StockOpnameRepository) GetByID(id int) (*models.StockOpname, error) {
+	o, err := scanOpname(repo.db.QueryRow("SELECT "+opnameColumns+" FROM stock_opnames o WHERE o.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("stock opname not found")
+	}
+	return o, err
+}
+
+// SubmitCounts records counted quantities for an open session. Sessions are
+// locked FOR SHARE so several devices can submit at once while posting waits.
+func (repo *StockOpnameRepository) SubmitCounts(id int, mode string, items []models.OpnameCountItem, countedBy *string) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockOpenOpname(tx, id, "FOR SHARE"); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		productID := item.ProductID
+		if item.Barcode != "" {
+			err := tx.QueryRow("SELECT product_id FROM product_barcodes WHERE code = $1", item.Barcode).Scan(&productID)
+			if err == sql.ErrNoRows {
+				return models.NewNotFoundError("no product with barcode %s", item.Barcode)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		result, err := tx.Exec(`
+			UPDATE stock_opname_items
+			SET counted_qty = CASE WHEN $1 = 'add' THEN COALESCE(counted_qty, 0) + $2 ELSE $2 END,
+				counted_by = $3,
+				counted_at = NOW()
+			WHERE opname_id = $4 AND product_id = $5
+		`, mode, item.Quantity, countedBy, id, productID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return models.NewNotFoundError("product id %d is not part of this stock opname", productID)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetVariance compares counted quantities with the snapshot, per product
+// and per category. Uncounted products are left out of the variance.
+func (repo *StockOpnameRepository) GetVariance(id int) (*models.OpnameVarianceReport, error) {
+	opname, err := repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT i.product_id, p.name, p.category_id, c.name, i.system_stock, i.counted_qty, i.unit_price
+		FROM stock_opname_items i
+		JOIN products p ON p.id = i.product_id
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE i.opname_id = $1
+		ORDER BY i.product_id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &models.OpnameVarianceReport{
+		Opname:     *opname,
+		Lines:      make([]models.OpnameVarianceLine, 0),
+		Categories: make([]models.OpnameCategoryVariance, 0),
+	}
+	byCategory := make(map[int]*models.OpnameCategoryVariance)
+	for rows.Next() {
+		var line models.OpnameVarianceLine
+		var counted *int
+		err := rows.Scan(&line.ProductID, &line.ProductName, &line.CategoryID, &line.CategoryName, &line.SystemStock, &counted, &line.UnitPrice)
+		if err != nil {
+			return nil, err
+		}
+		if counted == nil {
+			report.UncountedItems++
+			continue
+		}
+
+		line.CountedQty = *counted
+		line.VarianceQty = line.CountedQty - line.SystemStock
+		line.VarianceValue = line.VarianceQty * line.UnitPrice
+		report.Lines = append(report.Lines, line)
+		report.TotalVarianceQty += line.VarianceQty
+		report.TotalVarianceValue += line.VarianceValue
+
+		// produk tanpa category dikelompokkan dengan key 0
+		key := 0
+		if line.CategoryID != nil {
+			key = *line.CategoryID
+		}
+		group, ok := byCategory[key]
+		if !ok {
+			group = &models.OpnameCategoryVariance{CategoryID: line.CategoryID, CategoryName: line.CategoryName}
+			byCategory[key] = group
+		}
+		group.SystemQty += line.SystemStock
+		group.CountedQty += line.CountedQty
+		group.VarianceQty += line.VarianceQty
+		group.VarianceValue += line.VarianceValue
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]int, 0, len(byCategory))
+	for key := range byCategory {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	for _, key := range keys {
+		report.Categories = append(report.Categories, *byCategory[key])
+	}
+
+	return report, nil
+}
+
+// Post applies the variance of every counted product in one transaction.
+// The adjustment is counted minus snapshot, applied to the current stock,
+// so sales made after the snapshot are kept.
+func (repo *StockOpnameRepository) Post(id int, postedBy *string) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	outletID, err := lockOpenOpname(tx, id, "FOR UPDATE")
+	if err != nil {
+		return err
+	}
+
+	// urut berdasarkan product id, sama dengan urutan lock di checkout
+	rows, err := tx.Query(`
+		SELECT product_id, counted_qty - system_stock
+		FROM stock_opname_items
+		WHERE opname_id = $1 AND counted_qty IS NOT NULL AND counted_qty <> system_stock
+		ORDER BY product_id
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	type adjustment struct {
+		productID int
+		delta     int
+	}
+	adjustments := make([]adjustment, 0)
+	for rows.Next() {
+		var a adjustment
+		if err := rows.Scan(&a.productID, &a.delta); err != nil {
+			rows.Close()
+			return err
+		}
+		adjustments = append(adjustments, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	reference := models.StockRefOpname
+	for _, a := range adjustments {
+		err := adjustStock(tx, &models.StockMovement{
+			ProductID:     a.productID,
+			OutletID:      outletID,
+			Delta:         a.delta,
+			Reason:        models.StockReasonCorrection,
+			ReferenceType: &reference,
+			ReferenceID:   &id,
+			CreatedBy:     postedBy,
+			Note:          "stock opname",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE stock_opnames SET status = $1, posted_by = $2, posted_at = NOW() WHERE id = $3", models.OpnameStatusPosted, postedBy, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repo *StockOpnameRepository) Cancel(id int) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockOpenOpname(tx, id, "FOR UPDATE"); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE stock_opnames SET status = $1 WHERE id = $2", models.OpnameStatusCancelled, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockOpenOpname locks the session row, checks that it is still open and
+// returns its outlet
+func lockOpenOpname(tx *sql.Tx, id int, lock string) (int, error) {
+	var status string
+	var outletID int
+	err := tx.QueryRow("SELECT status, outlet_id FROM stock_opnames WHERE id = $1 "+lock, id).Scan(&status, &outletID)
+	if err == sql.ErrNoRows {
+		return 0, models.NewNotFoundError("stock opname not found")
+	}
+	if err != nil {
+		return 0, err
+	}
+	if status != models.OpnameStatusOpen {
+		return 0, models.NewConflictError("stock opname is already %s", status)
+	}
+	return outletID, nil
+}
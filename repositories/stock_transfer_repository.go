@@ -0,0 +1,228 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+	"sort"
+)
+
+type StockTransferRepository struct {
+	db *sql.DB
+}
+
+func NewStockTransferRepository(db *sql.DB) *StockTransferRepository {
+	return &StockTransferRepository{db: db}
+}
+
+const transferColumns = `
+	t.id, t.from_outlet_id, t.to_outlet_id, t.status, t.note, t.created_by, t.created_at, t.completed_by, t.completed_at`
+
+func scanTransfer(row rowScanner) (*models.StockTransfer, error) {
+	var t models.StockTransfer
+	err := row.Scan(&t.ID, &t.FromOutletID, &t.ToOutletID, &t.Status, &t.Note, &t.CreatedBy, &t.CreatedAt, &t.CompletedBy, &t.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Create takes the items out of the source outlet and leaves them in
+// transit until the transfer is received or cancelled
+func (repo *StockTransferRepository) Create(transfer *models.StockTransfer) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"INSERT INTO stock_transfers (from_outlet_id, to_outlet_id, status, note, created_by) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		transfer.FromOutletID, transfer.ToOutletID, models.TransferStatusInTransit, transfer.Note, transfer.CreatedBy,
+	).Scan(&transfer.ID, &transfer.CreatedAt)
+	if isForeignKeyViolation(err) {
+		return models.NewValidationError("outlet not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	requested := make(map[int]int)
+	for _, item := range transfer.Items {
+		requested[item.ProductID] += item.Quantity
+	}
+	if err := lockStock(tx, transfer.FromOutletID, requested); err != nil {
+		return err
+	}
+
+	for _, item := range transfer.Items {
+		_, err := tx.Exec(
+			"INSERT INTO stock_transfer_items (stock_transfer_id, product_id, quantity) VALUES ($1, $2, $3)",
+			transfer.ID, item.ProductID, item.Quantity,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := moveTransferStock(tx, transfer.ID, transfer.FromOutletID, -1, transfer.CreatedBy, "transfer out"); err != nil {
+		return err
+	}
+
+	transfer.Status = models.TransferStatusInTransit
+	return tx.Commit()
+}
+
+// GetAll - list transfers, newest first, optionally only one status
+func (repo *StockTransferRepository) GetAll(status string) ([]models.StockTransfer, error) {
+	rows, err := repo.db.Query(`
+		SELECT `+transferColumns+`
+		FROM stock_transfers t
+		WHERE $1 = '' OR t.status = $1
+		ORDER BY t.id DESC
+	`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := make([]models.StockTransfer, 0)
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, *t)
+	}
+
+	return transfers, rows.Err()
+}
+
+// GetByID - get transfer with its items
+func (repo *StockTransferRepository) GetByID(id int) (*models.StockTransfer, error) {
+	t, err := scanTransfer(repo.db.QueryRow("SELECT "+transferColumns+" FROM stock_transfers t WHERE t.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("stock transfer not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT i.product_id, p.name, i.quantity
+		FROM stock_transfer_items i
+		JOIN products p ON p.id = i.product_id
+		WHERE i.stock_transfer_id = $1
+		ORDER BY i.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	t.Items = make([]models.StockTransferItem, 0)
+	for rows.Next() {
+		var item models.StockTransferItem
+		if err := rows.Scan(&item.ProductID, &item.ProductName, &item.Quantity); err != nil {
+			return nil, err
+		}
+		t.Items = append(t.Items, item)
+	}
+
+	return t, rows.Err()
+}
+
+// Receive books the in-transit items into the destination outlet
+func (repo *StockTransferRepository) Receive(id int, completedBy *string) error {
+	return repo.complete(id, models.TransferStatusReceived, completedBy)
+}
+
+// Cancel returns the in-transit items to the source outlet
+func (repo *StockTransferRepository) Cancel(id int, completedBy *string) error {
+	return repo.complete(id, models.TransferStatusCancelled, completedBy)
+}
+
+func (repo *StockTransferRepository) complete(id int, status string, completedBy *string) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current string
+	var fromOutletID, toOutletID int
+	err = tx.QueryRow("SELECT status, from_outlet_id, to_outlet_id FROM stock_transfers WHERE id = $1 FOR UPDATE", id).
+		Scan(&current, &fromOutletID, &toOutletID)
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("stock transfer not found")
+	}
+	if err != nil {
+		return err
+	}
+	if current != models.TransferStatusInTransit {
+		return models.NewConflictError("stock transfer is already %s", current)
+	}
+
+	outletID, note := toOutletID, "transfer in"
+	if status == models.TransferStatusCancelled {
+		outletID, note = fromOutletID, "transfer cancelled"
+	}
+	if err := moveTransferStock(tx, id, outletID, 1, completedBy, note); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE stock_transfers SET status = $1, completed_by = $2, completed_at = NOW() WHERE id = $3", status, completedBy, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// moveTransferStock books every item of the transfer at one outlet, sign -1
+// takes stock out and 1 puts it in. Products are changed in ascending id
+// order like checkout.
+func moveTransferStock(tx *sql.Tx, transferID, outletID, sign int, createdBy *string, note string) error {
+	rows, err := tx.Query("SELECT product_id, SUM(quantity) FROM stock_transfer_items WHERE stock_transfer_id = $1 GROUP BY product_id", transferID)
+	if err != nil {
+		return err
+	}
+
+	quantities := make(map[int]int)
+	for rows.Next() {
+		var productID, quantity int
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		quantities[productID] = quantity
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	productIDs := make([]int, 0, len(quantities))
+	for productID := range quantities {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Ints(productIDs)
+
+	reference := models.StockRefTransfer
+	for _, productID := range productIDs {
+		err := adjustStock(tx, &models.StockMovement{
+			ProductID:     productID,
+			OutletID:      outletID,
+			Delta:         sign * quantities[productID],
+			Reason:        models.StockReasonTransfer,
+			ReferenceType: &reference,
+			ReferenceID:   &transferID,
+			CreatedBy:     createdBy,
+			Note:          note,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+)
+
+type SupplierRepository struct {
+	db *sql.DB
+}
+
+func NewSupplierRepository(db *sql.DB) *SupplierRepository {
+	return &SupplierRepository{db: db}
+}
+
+func (repo *SupplierRepository) GetAll() ([]models.Supplier, error) {
+	rows, err := repo.db.Query("SELECT id, name, phone, email, address, created_at FROM suppliers ORDER BY name, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppliers := make([]models.Supplier, 0)
+	for rows.Next() {
+		var s models.Supplier
+		err := rows.Scan(&s.ID, &s.Name, &s.Phone, &s.Email, &s.Address, &s.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, s)
+	}
+
+	return suppliers, rows.Err()
+}
+
+// GetByID - get supplier by ID
+func (repo *SupplierRepository) GetByID(id int) (*models.Supplier, error) {
+	var s models.Supplier
+	err := repo.db.QueryRow("SELECT id, name, phone, email, address, created_at FROM suppliers WHERE id = $1", id).
+		Scan(&s.ID, &s.Name, &s.Phone, &s.Email, &s.Address, &s.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("supplier not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (repo *SupplierRepository) Create(supplier *models.Supplier) error {
+	query := "INSERT INTO suppliers (name, phone, email, address) VALUES ($1, $2, $3, $4) RETURNING id, created_at"
+	return repo.db.QueryRow(query, supplier.Name, supplier.Phone, supplier.Email, supplier.Address).Scan(&supplier.ID, &supplier.CreatedAt)
+}
+
+func (repo *SupplierRepository) Update(supplier *models.Supplier) error {
+	query := "UPDATE suppliers SET name = $1, phone = $2, email = $3, address = $4 WHERE id = $5 RETURNING created_at"
+	err := repo.db.QueryRow(query, supplier.Name, supplier.Phone, supplier.Email, supplier.Address, supplier.ID).Scan(&supplier.CreatedAt)
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("supplier not found")
+	}
+	return err
+}
+
+func (repo *SupplierRepository) Delete(id int) error {
+	result, err := repo.db.Exec("DELETE FROM suppliers WHERE id = $1", id)
+	if isForeignKeyViolation(err) {
+		return models.NewConflictError("supplier has purchase orders or returns and cannot be deleted")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return models.NewNotFoundError("supplier not found")
+	}
+
+	return nil
+}
+
+// CreateReturn records goods sent back to a supplier and reduces stock
+func (repo *SupplierRepository) CreateReturn(ret *models.SupplierReturn) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"INSERT INTO supplier_returns (supplier_id, outlet_id, purchase_order_id, note, created_by) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		ret.SupplierID, ret.OutletID, ret.PurchaseOrderID, ret.Note, ret.CreatedBy,
+	).Scan(&ret.ID, &ret.CreatedAt)
+	if isForeignKeyViolation(err) {
+		return models.NewNotFoundError("supplier, outlet or purchase order not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if ret.PurchaseOrderID != nil {
+		var supplierID int
+		err := tx.QueryRow("SELECT supplier_id FROM purchase_orders WHERE id = $1", *ret.PurchaseOrderID).Scan(&supplierID)
+		if err != nil {
+			return err
+		}
+		if supplierID != ret.SupplierID {
+			return models.NewValidationError("purchase order %d belongs to another supplier", *ret.PurchaseOrderID)
+		}
+	}
+
+	requested := make(map[int]int)
+	for _, item := range ret.Items {
+		requested[item.ProductID] += item.Quantity
+	}
+	if err := lockStock(tx, ret.OutletID, requested); err != nil {
+		return err
+	}
+
+	reference := models.StockRefReturnOut
+	for _, item := range ret.Items {
+		_, err := tx.Exec(
+			"INSERT INTO supplier_return_items (supplier_return_id, product_id, quantity, unit_cost) VALUES ($1, $2, $3, $4)",
+			ret.ID, item.ProductID, item.Quantity, item.UnitCost,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = adjustStock(tx, &models.StockMovement{
+			ProductID:     item.ProductID,
+			OutletID:      ret.OutletID,
+			Delta:         -item.Quantity,
+			Reason:        models.StockReasonReturnOut,
+			ReferenceType: &reference,
+			ReferenceID:   &ret.ID,
+			CreatedBy:     ret.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetReturns - list returns sent to a supplier, newest first
+func (repo *SupplierRepository) GetReturns(supplierID int) ([]models.SupplierReturn, error) {
+	if _, err := repo.GetByID(supplierID); err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT r.id, r.supplier_id, r.outlet_id, r.purchase_order_id, r.note, r.created_by, r.created_at,
+			i.product_id, i.quantity, i.unit_cost
+		FROM supplier_returns r
+		JOIN supplier_return_items i ON i.supplier_return_id = r.id
+		WHERE r.supplier_id = $1
+		ORDER BY r.id DESC, i.id
+	`, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returns := make([]models.SupplierReturn, 0)
+	for rows.Next() {
+		var ret models.SupplierReturn
+		var item models.SupplierReturnItem
+		err := rows.Scan(&ret.ID, &ret.SupplierID, &ret.OutletID, &ret.PurchaseOrderID, &ret.Note, &ret.CreatedBy, &ret.CreatedAt,
+			&item.ProductID, &item.Quantity, &item.UnitCost)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(returns); n > 0 && returns[n-1].ID == ret.ID {
+			returns[n-1].Items = append(returns[n-1].Items, item)
+			continue
+		}
+		ret.Items = []models.SupplierReturnItem{item}
+		returns = append(returns, ret)
+	}
+
+	return returns, rows.Err()
+}
@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+	"math"
+	"sort"
+)
+
+// lineCharges is the service charge and tax of one checkout line
+type lineCharges struct {
+	profile *models.TaxProfile
+	service int
+	taxable int
+	tax     int
+}
+
+// chargeLine computes the service charge and tax of a line whose amount
+// after discounts is net. Service charge is a percentage of the line
+// without tax and is taxed like the line itself:
+//   - exclusive: tax = rate * (net + service)
+//   - inclusive: net already contains its tax, only the tax on the service
+//     charge is added on top
+func chargeLine(net int, profile *models.TaxProfile, serviceRate float64) lineCharges {
+	base, tax := net, 0
+	if profile != nil && profile.Inclusive {
+		base = roundHalfUp(float64(net) * 100 / (100 + profile.Rate))
+		tax = net - base
+	}
+
+	c := lineCharges{profile: profile, service: roundHalfUp(float64(base) * serviceRate / 100)}
+	c.taxable = base + c.service
+	if profile != nil {
+		if profile.Inclusive {
+			tax += roundHalfUp(float64(c.service) * profile.Rate / 100)
+		} else {
+			tax = roundHalfUp(float64(c.taxable) * profile.Rate / 100)
+		}
+	}
+	c.tax = tax
+
+	return c
+}
+
+// roundTotal rounds the transaction total to a multiple of unit and returns
+// the adjustment, which is negative when rounded down. Up and down are
+// towards positive and negative infinity, also for negative totals.
+func roundTotal(total, unit int, mode string) int {
+	if unit <= 1 {
+		return 0
+	}
+
+	// sisa selalu positif supaya total negatif dibulatkan dengan arah yang sama
+	remainder := (total%unit + unit) % unit
+	if remainder == 0 {
+		return 0
+	}
+	switch mode {
+	case models.RoundingUp:
+		return unit - remainder
+	case models.RoundingDown:
+		return -remainder
+	}
+	if remainder*2 >= unit {
+		return unit - remainder
+	}
+	return -remainder
+}
+
+func roundHalfUp(value float64) int {
+	return int(math.Floor(value + 0.5))
+}
+
+// summarizeTaxes groups the taxed lines per profile, ordered by profile id
+func summarizeTaxes(details []models.TransactionDetail) []models.TransactionTax {
+	taxes := make([]models.TransactionTax, 0)
+	index := make(map[int]int)
+	for _, d := range details {
+		if d.TaxProfileID == nil {
+			continue
+		}
+		i, ok := index[*d.TaxProfileID]
+		if !ok {
+			i = len(taxes)
+			index[*d.TaxProfileID] = i
+			taxes = append(taxes, models.TransactionTax{
+				TaxProfileID: *d.TaxProfileID,
+				Name:         *d.TaxName,
+				Rate:         d.TaxRate,
+				Inclusive:    d.TaxInclusive,
+			})
+		}
+		taxes[i].TaxableAmount += d.TaxableAmount
+		taxes[i].Amount += d.Tax
+	}
+
+	sort.Slice(taxes, func(i, j int) bool { return taxes[i].TaxProfileID < taxes[j].TaxProfileID })
+	return taxes
+}
+
+// resolveTaxProfile returns the profile of a product: its own, else the
+// one of its category or the nearest ancestor category that has one
+func resolveTaxProfile(productProfileID, categoryID *int, categories map[int]taxCategory, profiles map[int]models.TaxProfile) *models.TaxProfile {
+	profileID := productProfileID
+	// batasi jumlah langkah supaya data yang berputar tidak membuat loop
+	for steps := 0; profileID == nil && categoryID != nil && steps < len(categories); steps++ {
+		c, ok := categories[*categoryID]
+		if !ok {
+			break
+		}
+		profileID = c.taxProfileID
+		categoryID = c.parentID
+	}
+
+	if profileID == nil {
+		return nil
+	}
+	profile, ok := profiles[*profileID]
+	if !ok {
+		return nil
+	}
+	return &profile
+}
+
+type taxCategory struct {
+	parentID     *int
+	taxProfileID *int
+}
+
+// loadTaxSettings reads every tax profile and the tax profile and parent of
+// every category
+func loadTaxSettings(tx *sql.Tx) (map[int]models.TaxProfile, map[int]taxCategory, error) {
+	rows, err := tx.Query("SELECT id, name, rate, inclusive, created_at FROM tax_profiles")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	profiles := make(map[int]models.TaxProfile)
+	for rows.Next() {
+		var t models.TaxProfile
+		if err := rows.Scan(&t.ID, &t.Name, &t.Rate, &t.Inclusive, &t.CreatedAt); err != nil {
+			return nil, nil, err
+		}
+		profiles[t.ID] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err = tx.Query("SELECT id, parent_id, tax_profile_id FROM categories")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	categories := make(map[int]taxCategory)
+	for rows.Next() {
+		var id int
+		var c taxCategory
+		if err := rows.Scan(&id, &c.parentID, &c.taxProfileID); err != nil {
+			return nil, nil, err
+		}
+		categories[id] = c
+	}
+
+	return profiles, categories, rows.Err()
+}
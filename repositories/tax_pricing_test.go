@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"kasir-api/models"
+	"testing"
+)
+
+func TestChargeLine(t *testing.T) {
+	ppn := &models.TaxProfile{ID: 1, Name: "PPN", Rate: 11}
+	ppnIncl := &models.TaxProfile{ID: 2, Name: "PPN", Rate: 11, Inclusive: true}
+	zero := &models.TaxProfile{ID: 3, Name: "Bebas", Rate: 0}
+
+	tests := []struct {
+		name        string
+		net         int
+		profile     *models.TaxProfile
+		serviceRate float64
+		want        lineCharges
+	}{
+		{"no tax", 10000, nil, 0, lineCharges{taxable: 10000}},
+		{"service without tax", 10000, nil, 5, lineCharges{service: 500, taxable: 10500}},
+		{"exclusive", 10000, ppn, 0, lineCharges{taxable: 10000, tax: 1100}},
+		{"exclusive taxes the service charge", 10000, ppn, 5, lineCharges{service: 500, taxable: 10500, tax: 1155}},
+		{"inclusive", 11100, ppnIncl, 0, lineCharges{taxable: 10000, tax: 1100}},
+		{"inclusive adds tax on the service charge only", 11100, ppnIncl, 10, lineCharges{service: 1000, taxable: 11000, tax: 1210}},
+		{"inclusive base is rounded", 10000, ppnIncl, 0, lineCharges{taxable: 9009, tax: 991}},
+		{"exclusive rounds half up", 50, ppn, 0, lineCharges{taxable: 50, tax: 6}},
+		{"exclusive just below half", 45, ppn, 0, lineCharges{taxable: 45, tax: 5}},
+		{"exclusive rounds down", 4, ppn, 0, lineCharges{taxable: 4, tax: 0}},
+		{"service rounds half up", 10, nil, 5, lineCharges{service: 1, taxable: 11}},
+		{"zero rate", 10000, zero, 0, lineCharges{taxable: 10000}},
+		{"zero net", 0, ppn, 5, lineCharges{}},
+		{"fully discounted inclusive line", 0, ppnIncl, 10, lineCharges{}},
+		{"negative net", -10000, ppn, 0, lineCharges{taxable: -10000, tax: -1100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := chargeLine(tt.net, tt.profile, tt.serviceRate)
+			if got.profile != tt.profile {
+				t.Errorf("profile = %v, want %v", got.profile, tt.profile)
+			}
+			got.profile = nil
+			if got != tt.want {
+				t.Errorf("chargeLine(%d) = %+v, want %+v", tt.net, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundTotal(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int
+		unit  int
+		mode  string
+		want  int
+	}{
+		{"no unit", 12345, 0, models.RoundingNearest, 0},
+		{"unit of one", 12345, 1, models.RoundingNearest, 0},
+		{"negative unit", 12345, -100, models.RoundingNearest, 0},
+		{"already rounded", 12400, 100, models.RoundingNearest, 0},
+		{"zero total", 0, 100, models.RoundingUp, 0},
+		{"nearest, half goes up", 12450, 100, models.RoundingNearest, 50},
+		{"nearest, just below half", 12449, 100, models.RoundingNearest, -49},
+		{"nearest, just above half", 12451, 100, models.RoundingNearest, 49},
+		{"nearest is the default mode", 12451, 100, "", 49},
+		{"nearest with unit 500", 12250, 500, models.RoundingNearest, 250},
+		{"nearest with unit 500 below half", 12249, 500, models.RoundingNearest, -249},
+		{"up", 12401, 100, models.RoundingUp, 99},
+		{"down", 12499, 100, models.RoundingDown, -99},
+		{"total smaller than the unit", 40, 100, models.RoundingNearest, -40},
+		{"negative, nearest", -12450, 100, models.RoundingNearest, 50},
+		{"negative, up", -12401, 100, models.RoundingUp, 1},
+		{"negative, down", -12401, 100, models.RoundingDown, -99},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := roundTotal(tt.total, tt.unit, tt.mode)
+			if got != tt.want {
+				t.Errorf("roundTotal(%d, %d, %q) = %d, want %d", tt.total, tt.unit, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
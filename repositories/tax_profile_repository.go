@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+)
+
+type TaxProfileRepository struct {
+	db *sql.DB
+}
+
+func NewTaxProfileRepository(db *sql.DB) *TaxProfileRepository {
+	return &TaxProfileRepository{db: db}
+}
+
+func (repo *TaxProfileRepository) GetAll() ([]models.TaxProfile, error) {
+	rows, err := repo.db.Query("SELECT id, name, rate, inclusive, created_at FROM tax_profiles ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := make([]models.TaxProfile, 0)
+	for rows.Next() {
+		var t models.TaxProfile
+		if err := rows.Scan(&t.ID, &t.Name, &t.Rate, &t.Inclusive, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, t)
+	}
+
+	return profiles, rows.Err()
+}
+
+// GetByID - get tax profile by ID
+func (repo *TaxProfileRepository) GetByID(id int) (*models.TaxProfile, error) {
+	var t models.TaxProfile
+	err := repo.db.QueryRow("SELECT id, name, rate, inclusive, created_at FROM tax_profiles WHERE id = $1", id).
+		Scan(&t.ID, &t.Name, &t.Rate, &t.Inclusive, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("tax profile not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (repo *TaxProfileRepository) Create(profile *models.TaxProfile) error {
+	query := "INSERT INTO tax_profiles (name, rate, inclusive) VALUES ($1, $2, $3) RETURNING id, created_at"
+	err := repo.db.QueryRow(query, profile.Name, profile.Rate, profile.Inclusive).Scan(&profile.ID, &profile.CreatedAt)
+	return translateTaxProfileError(err)
+}
+
+// Update changes the profile for future sales, past transactions keep the
+// rate they were sold with
+func (repo *TaxProfileRepository) Update(profile *models.TaxProfile) error {
+	query := "UPDATE tax_profiles SET name = $1, rate = $2, inclusive = $3 WHERE id = $4 RETURNING created_at"
+	err := repo.db.QueryRow(query, profile.Name, profile.Rate, profile.Inclusive, profile.ID).Scan(&profile.CreatedAt)
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("tax profile not found")
+	}
+	return translateTaxProfileError(err)
+}
+
+// Delete removes a profile not assigned to any product or category and
+// never used in a sale
+func (repo *TaxProfileRepository) Delete(id int) error {
+	result, err := repo.db.Exec("DELETE FROM tax_profiles WHERE id = $1", id)
+	if isForeignKeyViolation(err) {
+		return models.NewConflictError("tax profile is assigned to products or categories or used in transactions")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return models.NewNotFoundError("tax profile not found")
+	}
+
+	return nil
+}
+
+// translateTaxProfileError turns constraint violations into client errors
+func translateTaxProfileError(err error) error {
+	if isUniqueViolation(err) {
+		return models.NewConflictError("tax profile with the same name already exists")
+	}
+	return err
+}
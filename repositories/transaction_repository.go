@@ -2,8 +2,12 @@ package repositories
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"kasir-api/models"
+	"sort"
+	"strings"
+	"time"
 )
 
 type TransactionRepository struct {
@@ -14,7 +18,11 @@ func NewTransactionRepository(db *sql.DB) *TransactionRepository {
 	return &TransactionRepository{db: db}
 }
 
-func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem) (*models.Transaction, error) {
+// CreateTransaction sells the items at the outlet. req must be normalized by
+// the service: Payments filled in and CouponCode upper-cased. With an
+// idempotency key a retry returns the stored response instead of selling
+// again. A sale to a customer earns and redeems points as set by loyalty.
+func (repo *TransactionRepository) CreateTransaction(outletID int, req models.CheckoutRequest, createdBy *string, idempotency *models.IdempotencyKey, loyalty models.LoyaltyConfig) (*models.Transaction, error) {
 	var (
 		res *models.Transaction
 	)
@@ -25,87 +33,1158 @@ func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem
 	}
 	defer tx.Rollback()
 
-	// inisialisasi subtotal -> jumlah total transaksi keseluruhan
-	totalAmount := 0
-	// inisialisasi modeling transactionDetails -> nanti kita insert ke db
-	details := make([]models.TransactionDetail, 0)
-	// loop setiap item
-	for _, item := range items {
-		var productName string
-		var productID, price, stock int
-		// get product dapet pricing
-		err := tx.QueryRow("SELECT id, name, price, stock FROM products WHERE id=$1", item.ProductID).Scan(&productID, &productName, &price, &stock)
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("product id %d not found", item.ProductID)
+	if idempotency != nil {
+		stored, err := claimIdempotencyKey(tx, idempotency)
+		if err != nil {
+			return nil, err
 		}
+		if stored != nil {
+			idempotency.Replayed = true
+			return stored, nil
+		}
+	}
 
+	// service charge dan pembulatan mengikuti setting outlet
+	var outlet models.Outlet
+	err = tx.QueryRow("SELECT service_charge_rate, rounding_unit, rounding_mode FROM outlets WHERE id = $1", outletID).
+		Scan(&outlet.ServiceChargeRate, &outlet.RoundingUnit, &outlet.RoundingMode)
+	if err == sql.ErrNoRows {
+		return nil, models.NewValidationError("outlet %d not found", outletID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// customer dikunci sebelum product, poin yang kadaluarsa dibukukan dulu
+	pointsBalance := 0
+	if req.CustomerID != nil {
+		if err := lockCustomer(tx, *req.CustomerID); err != nil {
+			return nil, err
+		}
+		pointsBalance, err = expirePoints(tx, *req.CustomerID, createdBy)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		// hitung current total = quantity * pricing
-		// ditambahin ke dalam subtotal
-		subtotal := item.Quantity * price
-		totalAmount += subtotal
+	// gabungkan item dengan product yang sama, urutan pertama muncul dipertahankan
+	quantities := make(map[int]int)
+	order := make([]int, 0, len(req.Items))
+	for _, item := range req.Items {
+		productID := item.ProductID
+		if item.Barcode != "" {
+			// scan barcode -> cari product id nya
+			err := tx.QueryRow("SELECT product_id FROM product_barcodes WHERE code = $1", item.Barcode).Scan(&productID)
+			if err == sql.ErrNoRows {
+				return nil, models.NewNotFoundError("no product with barcode %s", item.Barcode)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
 
-		// kurangi jumlah stok
-		_, err = tx.Exec("UPDATE products SET stock = stock - $1 WHERE id = $2", item.Quantity, productID)
+		if _, ok := quantities[productID]; !ok {
+			order = append(order, productID)
+		}
+		quantities[productID] += item.Quantity
+	}
+
+	// lock row product berurutan berdasarkan id supaya checkout paralel tidak deadlock,
+	// stok yang dicek adalah stok di outlet tempat checkout
+	lockOrder := make([]int, len(order))
+	copy(lockOrder, order)
+	sort.Ints(lockOrder)
+
+	type lockedProduct struct {
+		name         string
+		price        int
+		stock        int
+		isParent     bool
+		parentID     *int
+		categoryID   *int
+		taxProfileID *int
+	}
+	products := make(map[int]lockedProduct, len(lockOrder))
+	shortages := make([]models.StockShortage, 0)
+	for _, productID := range lockOrder {
+		var p lockedProduct
+		err := tx.QueryRow(`
+			SELECT p.name, p.price, p.options IS NOT NULL, p.parent_id, p.category_id,
+				COALESCE(p.tax_profile_id, pp.tax_profile_id)
+			FROM products p
+			LEFT JOIN products pp ON pp.id = p.parent_id
+			WHERE p.id = $1
+			FOR UPDATE OF p
+		`, productID).Scan(&p.name, &p.price, &p.isParent, &p.parentID, &p.categoryID, &p.taxProfileID)
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundError("product id %d not found", productID)
+		}
 		if err != nil {
 			return nil, err
 		}
+		if p.isParent {
+			return nil, models.NewValidationError("product id %d has variants, sell one of its variants", productID)
+		}
+		// stok dibaca ulang setelah product terkunci, bukan dari snapshot query di atas
+		p.stock, err = lockStockRow(tx, productID, outletID)
+		if err != nil {
+			return nil, err
+		}
+		products[productID] = p
 
-		// item nya dimasukkin ke transactionDetails
-		details = append(details, models.TransactionDetail{
-			ProductID:   productID,
-			ProductName: productName,
-			Quantity:    item.Quantity,
-			Subtotal:    subtotal,
+		if quantities[productID] > p.stock {
+			shortages = append(shortages, models.StockShortage{
+				ProductID: productID,
+				Requested: quantities[productID],
+				Available: p.stock,
+			})
+		}
+	}
+
+	// satu saja kurang stok, seluruh transaksi dibatalkan
+	if len(shortages) > 0 {
+		return nil, &models.InsufficientStockError{Shortages: shortages}
+	}
+
+	// hitung subtotal (quantity * price) per baris
+	lines := make([]pricedLine, 0, len(order))
+	for _, productID := range order {
+		p := products[productID]
+		quantity := quantities[productID]
+		lines = append(lines, pricedLine{
+			productID:  productID,
+			parentID:   p.parentID,
+			categoryID: p.categoryID,
+			quantity:   quantity,
+			price:      p.price,
+			gross:      quantity * p.price,
 		})
 	}
 
+	// terapkan promo yang berlaku
+	promotions, err := loadActivePromotions(tx, req.CouponCode)
+	if err != nil {
+		return nil, err
+	}
+	categoryParents, err := loadCategoryParents(tx)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := applyPromotions(lines, promotions, categoryParents, req.CouponCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var couponCode *string
+	for _, a := range applied {
+		if a.promotion.CouponCode == nil {
+			continue
+		}
+		// kuota kupon dicek dan dipakai secara atomik
+		result, err := tx.Exec(`
+			UPDATE promotions SET usage_count = usage_count + 1
+			WHERE id = $1 AND (usage_limit IS NULL OR usage_count < usage_limit)
+		`, a.promotion.ID)
+		if err != nil {
+			return nil, err
+		}
+		if rows, err := result.RowsAffected(); err != nil {
+			return nil, err
+		} else if rows == 0 {
+			return nil, models.NewConflictError("coupon %s has reached its usage limit", *a.promotion.CouponCode)
+		}
+		couponCode = a.promotion.CouponCode
+	}
+
+	// poin ditukar jadi potongan, dibagi ke baris seperti diskon cart
+	pointsDiscount := 0
+	if req.RedeemPoints > 0 {
+		if req.RedeemPoints > pointsBalance {
+			return nil, models.NewValidationError("redeem_points exceeds the customer's balance of %d points", pointsBalance)
+		}
+		net := 0
+		for _, line := range lines {
+			net += line.gross - line.discount
+		}
+		pointsDiscount = req.RedeemPoints * loyalty.PointValue
+		if pointsDiscount > net {
+			return nil, models.NewValidationError("at most %d points can be redeemed on this cart", net/loyalty.PointValue)
+		}
+		shareCartDiscount(lines, net, pointsDiscount)
+	}
+
+	// pajak dan service charge dihitung per baris setelah diskon
+	taxProfiles, taxCategories, err := loadTaxSettings(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	grossAmount, discountAmount, serviceAmount, taxAmount, totalAmount := 0, 0, 0, 0, 0
+	details := make([]models.TransactionDetail, 0, len(lines))
+	for _, line := range lines {
+		p := products[line.productID]
+		profile := resolveTaxProfile(p.taxProfileID, p.categoryID, taxCategories, taxProfiles)
+		charges := chargeLine(line.gross-line.discount, profile, outlet.ServiceChargeRate)
+
+		detail := models.TransactionDetail{
+			ProductID:     line.productID,
+			ProductName:   p.name,
+			Quantity:      line.quantity,
+			Subtotal:      line.gross,
+			Discount:      line.discount,
+			Service:       charges.service,
+			TaxableAmount: charges.taxable,
+			Tax:           charges.tax,
+		}
+		if profile != nil {
+			detail.TaxProfileID = &profile.ID
+			detail.TaxName = &profile.Name
+			detail.TaxRate = profile.Rate
+			detail.TaxInclusive = profile.Inclusive
+		}
+		details = append(details, detail)
+
+		grossAmount += line.gross
+		discountAmount += line.discount
+		serviceAmount += charges.service
+		taxAmount += charges.tax
+		totalAmount += charges.taxable + charges.tax
+	}
+	subtotalAmount := grossAmount - discountAmount
+	roundingAmount := roundTotal(totalAmount, outlet.RoundingUnit, outlet.RoundingMode)
+	totalAmount += roundingAmount
+
+	payments, tendered, change, err := settlePayments(totalAmount, req.Payments)
+	if err != nil {
+		return nil, err
+	}
+	paymentMethod := payments[0].Method
+	for _, payment := range payments {
+		if payment.Method != paymentMethod {
+			paymentMethod = models.PaymentSplit
+		}
+	}
+
+	var buyerName, buyerAddress, buyerNPWP *string
+	if req.Buyer != nil {
+		buyerName, buyerAddress, buyerNPWP = &req.Buyer.Name, &req.Buyer.Address, &req.Buyer.NPWP
+	}
+
+	pointsEarned := 0
+	if req.CustomerID != nil && loyalty.EarnEnabled() {
+		multipliers, err := loadPointsMultipliers(tx)
+		if err != nil {
+			return nil, err
+		}
+		pointsEarned = earnedPoints(lines, multipliers, loyalty)
+	}
+
+	// transaksi masuk ke shift kasir yang sedang buka
+	shiftID, err := lockOpenShift(tx, outletID, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	takesCash := false
+	for _, payment := range payments {
+		if payment.Method == models.PaymentCash {
+			takesCash = true
+		}
+	}
+	if takesCash {
+		needsShift, err := cashNeedsShift(tx, outletID, shiftID)
+		if err != nil {
+			return nil, err
+		}
+		if needsShift {
+			return nil, models.NewConflictError("this outlet uses shifts, open a shift before taking cash")
+		}
+	}
+
 	// insert transaction
 	var transactionID int
-	err = tx.QueryRow("INSERT INTO transactions (total_amount) VALUES ($1) RETURNING ID", totalAmount).Scan(&transactionID)
+	var createdAt time.Time
+	err = tx.QueryRow(
+		`INSERT INTO transactions (outlet_id, shift_id, customer_id, gross_amount, discount_amount, subtotal_amount, service_amount, tax_amount, rounding_amount,
+			total_amount, coupon_code, points_discount, payment_method, amount_tendered, change_amount, created_by,
+			buyer_name, buyer_address, buyer_npwp)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, NULLIF($18, ''), NULLIF($19, '')) RETURNING id, created_at`,
+		outletID, shiftID, req.CustomerID, grossAmount, discountAmount, subtotalAmount, serviceAmount, taxAmount, roundingAmount,
+		totalAmount, couponCode, pointsDiscount, paymentMethod, tendered, change, createdBy,
+		buyerName, buyerAddress, buyerNPWP,
+	).Scan(&transactionID, &createdAt)
+	if isForeignKeyViolation(err) {
+		return nil, models.NewValidationError("outlet %d not found", outletID)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	for i, payment := range payments {
+		err := tx.QueryRow(
+			"INSERT INTO transaction_payments (transaction_id, method, amount, change_amount) VALUES ($1, $2, $3, $4) RETURNING id",
+			transactionID, payment.Method, payment.Amount, payment.Change,
+		).Scan(&payments[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// insert transaction details
+	reference := models.StockRefTransaction
 	for i, detail := range details {
+		// kurangi jumlah stok, tercatat di stock ledger
+		err := adjustStock(tx, &models.StockMovement{
+			ProductID:     detail.ProductID,
+			OutletID:      outletID,
+			Delta:         -detail.Quantity,
+			Reason:        models.StockReasonSale,
+			ReferenceType: &reference,
+			ReferenceID:   &transactionID,
+			CreatedBy:     createdBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+
 		details[i].TransactionID = transactionID
-		err := tx.QueryRow("INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal) VALUES ($1, $2, $3, $4) RETURNING id", transactionID, detail.ProductID, detail.Quantity, detail.Subtotal).Scan(&details[i].ID)
+		err = tx.QueryRow(
+			`INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal, discount_amount,
+				tax_profile_id, tax_name, tax_rate, tax_inclusive, service_amount, taxable_amount, tax_amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`,
+			transactionID, detail.ProductID, detail.Quantity, detail.Subtotal, detail.Discount,
+			detail.TaxProfileID, detail.TaxName, detail.TaxRate, detail.TaxInclusive, detail.Service, detail.TaxableAmount, detail.Tax,
+		).Scan(&details[i].ID)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, err
+	discounts := make([]models.AppliedDiscount, 0, len(applied))
+	for _, a := range applied {
+		discount := models.AppliedDiscount{PromotionID: a.promotion.ID, Name: a.promotion.Name, Amount: a.amount}
+		if a.line >= 0 {
+			discount.DetailID = &details[a.line].ID
+		}
+		_, err := tx.Exec(
+			"INSERT INTO transaction_discounts (transaction_id, transaction_detail_id, promotion_id, name, amount) VALUES ($1, $2, $3, $4, $5)",
+			transactionID, discount.DetailID, discount.PromotionID, discount.Name, discount.Amount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		discounts = append(discounts, discount)
+	}
+
+	if req.CustomerID != nil {
+		err := postPoints(tx, *req.CustomerID, &transactionID, models.PointsRedeem, -req.RedeemPoints, 0, createdBy)
+		if err != nil {
+			return nil, err
+		}
+		err = postPoints(tx, *req.CustomerID, &transactionID, models.PointsEarn, pointsEarned, loyalty.Expiry, createdBy)
+		if err != nil {
+			return nil, err
+		}
+		pointsBalance += pointsEarned - req.RedeemPoints
 	}
 
 	res = &models.Transaction{
-		ID:          transactionID,
-		TotalAmount: totalAmount,
-		Details:     details,
+		ID:             transactionID,
+		OutletID:       outletID,
+		ShiftID:        shiftID,
+		CustomerID:     req.CustomerID,
+		Status:         models.TransactionStatusCompleted,
+		CreatedBy:      createdBy,
+		GrossAmount:    grossAmount,
+		DiscountAmount: discountAmount,
+		SubtotalAmount: subtotalAmount,
+		ServiceAmount:  serviceAmount,
+		TaxAmount:      taxAmount,
+		RoundingAmount: roundingAmount,
+		TotalAmount:    totalAmount,
+		Taxes:          summarizeTaxes(details),
+		CouponCode:     couponCode,
+		Buyer:          req.Buyer,
+		Discounts:      discounts,
+		PointsDiscount: pointsDiscount,
+		PointsRedeemed: req.RedeemPoints,
+		PointsEarned:   pointsEarned,
+		PaymentMethod:  paymentMethod,
+		AmountTendered: tendered,
+		Change:         change,
+		Payments:       payments,
+		Details:        details,
+		CreatedAt:      createdAt,
+	}
+	if req.CustomerID != nil {
+		res.PointsBalance = &pointsBalance
+	}
+
+	if idempotency != nil {
+		response, err := json.Marshal(res)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.Exec("UPDATE idempotency_keys SET transaction_id = $1, response = $2 WHERE key = $3", transactionID, string(response), idempotency.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
 	return res, nil
 }
 
-func (repo *TransactionRepository) GetTodaySalesSummary() (*models.DailySalesSummary, error) {
+// claimIdempotencyKey reserves the key for this checkout. When the key was
+// already used it returns the stored response, or a conflict if the request
+// differs. A concurrent request with the same key waits on the insert until
+// the first one commits or rolls back.
+func claimIdempotencyKey(tx *sql.Tx, idempotency *models.IdempotencyKey) (*models.Transaction, error) {
+	// key yang sudah lewat masa simpan dibuang
+	_, err := tx.Exec("DELETE FROM idempotency_keys WHERE created_at < NOW() - make_interval(secs => $1)", idempotency.Retention.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Exec(
+		"INSERT INTO idempotency_keys (key, fingerprint) VALUES ($1, $2) ON CONFLICT (key) DO NOTHING",
+		idempotency.Key, idempotency.Fingerprint,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if rows == 1 {
+		return nil, nil
+	}
+
+	var fingerprint string
+	var response *string
+	err = tx.QueryRow("SELECT fingerprint, response FROM idempotency_keys WHERE key = $1", idempotency.Key).Scan(&fingerprint, &response)
+	if err != nil {
+		return nil, err
+	}
+	if fingerprint != idempotency.Fingerprint {
+		return nil, models.NewConflictError("Idempotency-Key %s was already used with a different request", idempotency.Key)
+	}
+	if response == nil {
+		return nil, models.NewConflictError("Idempotency-Key %s is still being processed", idempotency.Key)
+	}
+
+	var stored models.Transaction
+	if err := json.Unmarshal([]byte(*response), &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// CreateRefund returns items of a sale as a new transaction with negative
+// amounts linked to the original. Amounts are taken pro rata from the sold
+// line, so refunding a line in several steps adds up to exactly what was
+// paid for it. Returned goods go back into the stock of the original outlet
+// unless marked damaged.
+func (repo *TransactionRepository) CreateRefund(transactionID int, req models.RefundRequest, createdBy *string, pointsExpiry time.Duration) (*models.Transaction, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// lock transaksi asal supaya refund paralel tidak melebihi jumlah terjual
+	var outletID int
+	var refundOfID *int
+	var paymentMethod string
+	var customerID *int
+	var voided bool
+	var saleTotal, saleRounding, refundedTotal int
+	err = tx.QueryRow(`
+		SELECT outlet_id, refund_of_id, payment_method, customer_id, voided_at IS NOT NULL, total_amount, rounding_amount,
+			COALESCE((SELECT -SUM(r.total_amount - r.rounding_amount) FROM transactions r WHERE r.refund_of_id = t.id), 0)
+		FROM transactions t
+		WHERE id = $1
+		FOR UPDATE
+	`, transactionID).Scan(&outletID, &refundOfID, &paymentMethod, &customerID, &voided, &saleTotal, &saleRounding, &refundedTotal)
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("transaction not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if refundOfID != nil {
+		return nil, models.NewValidationError("transaction %d is a refund and cannot be refunded", transactionID)
+	}
+	if voided {
+		return nil, models.NewConflictError("transaction %d is voided", transactionID)
+	}
+	if customerID != nil {
+		if err := lockCustomer(tx, *customerID); err != nil {
+			return nil, err
+		}
+	}
+
+	type soldLine struct {
+		detail   models.TransactionDetail
+		refunded int
+	}
+	rows, err := tx.Query(`
+		SELECT td.id, td.product_id, p.name, td.quantity, td.subtotal, td.discount_amount,
+			td.tax_profile_id, td.tax_name, td.tax_rate, td.tax_inclusive, td.service_amount, td.taxable_amount, td.tax_amount,
+			COALESCE((SELECT -SUM(r.quantity) FROM transaction_details r WHERE r.refunded_detail_id = td.id), 0)
+		FROM transaction_details td
+		JOIN products p ON p.id = td.product_id
+		WHERE td.transaction_id = $1
+		ORDER BY td.id
+	`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sold := make(map[int]*soldLine)
+	byProduct := make(map[int]int)
+	for rows.Next() {
+		var l soldLine
+		d := &l.detail
+		err := rows.Scan(&d.ID, &d.ProductID, &d.ProductName, &d.Quantity, &d.Subtotal, &d.Discount,
+			&d.TaxProfileID, &d.TaxName, &d.TaxRate, &d.TaxInclusive, &d.Service, &d.TaxableAmount, &d.Tax, &l.refunded)
+		if err != nil {
+			return nil, err
+		}
+		sold[d.ID] = &l
+		byProduct[d.ProductID] = d.ID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// porsi kumulatif, refund terakhir mengambil sisa pembulatan
+	share := func(amount, before, after, quantity int) int {
+		return amount*after/quantity - amount*before/quantity
+	}
+
+	details := make([]models.TransactionDetail, 0, len(req.Items))
+	for i, item := range req.Items {
+		detailID := item.DetailID
+		if detailID == 0 {
+			id, ok := byProduct[item.ProductID]
+			if !ok {
+				return nil, models.NewValidationError("items[%d]: product %d was not sold in transaction %d", i, item.ProductID, transactionID)
+			}
+			detailID = id
+		}
+		line, ok := sold[detailID]
+		if !ok {
+			return nil, models.NewValidationError("items[%d]: detail %d does not belong to transaction %d", i, detailID, transactionID)
+		}
+
+		d := line.detail
+		if item.Quantity > d.Quantity-line.refunded {
+			return nil, models.NewValidationError("items[%d]: quantity %d exceeds the %d still refundable", i, item.Quantity, d.Quantity-line.refunded)
+		}
+		before, after := line.refunded, line.refunded+item.Quantity
+		line.refunded = after
+
+		details = append(details, models.TransactionDetail{
+			ProductID:        d.ProductID,
+			ProductName:      d.ProductName,
+			Quantity:         -item.Quantity,
+			Subtotal:         -share(d.Subtotal, before, after, d.Quantity),
+			Discount:         -share(d.Discount, before, after, d.Quantity),
+			TaxProfileID:     d.TaxProfileID,
+			TaxName:          d.TaxName,
+			TaxRate:          d.TaxRate,
+			TaxInclusive:     d.TaxInclusive,
+			Service:          -share(d.Service, before, after, d.Quantity),
+			TaxableAmount:    -share(d.TaxableAmount, before, after, d.Quantity),
+			Tax:              -share(d.Tax, before, after, d.Quantity),
+			RefundedDetailID: &d.ID,
+			Damaged:          item.Damaged,
+		})
+	}
+
+	refund := models.Transaction{
+		OutletID:     outletID,
+		CustomerID:   customerID, // refund tercatat di riwayat customer yang sama
+		Status:       models.TransactionStatusRefund,
+		RefundOfID:   &transactionID,
+		RefundReason: req.Reason,
+		CreatedBy:    createdBy,
+		Discounts:    make([]models.AppliedDiscount, 0),
+		Details:      details,
+	}
+	for _, d := range details {
+		refund.GrossAmount += d.Subtotal
+		refund.DiscountAmount += d.Discount
+		refund.ServiceAmount += d.Service
+		refund.TaxAmount += d.Tax
+		refund.TotalAmount += d.TaxableAmount + d.Tax
+	}
+	refund.SubtotalAmount = refund.GrossAmount - refund.DiscountAmount
+	refund.Taxes = summarizeTaxes(details)
+
+	// pembulatan penjualan ikut dikembalikan sebanding dengan total sebelum
+	// pembulatan, kumulatif supaya refund seluruhnya mengembalikan semuanya
+	if saleBeforeRounding := saleTotal - saleRounding; saleBeforeRounding > 0 {
+		before := saleRounding * refundedTotal / saleBeforeRounding
+		after := saleRounding * (refundedTotal - refund.TotalAmount) / saleBeforeRounding
+		refund.RoundingAmount = -(after - before)
+		refund.TotalAmount += refund.RoundingAmount
+	}
+
+	// uang dikembalikan dengan metode pembayaran asal, split dikembalikan tunai
+	refund.PaymentMethod = req.PaymentMethod
+	if refund.PaymentMethod == "" {
+		refund.PaymentMethod = paymentMethod
+		if paymentMethod == models.PaymentSplit {
+			refund.PaymentMethod = models.PaymentCash
+		}
+	}
+	refund.AmountTendered = refund.TotalAmount
+	refund.Payments = []models.TransactionPayment{{Method: refund.PaymentMethod, Amount: refund.TotalAmount}}
+
+	// uang refund keluar dari laci shift kasir yang memproses
+	refund.ShiftID, err = lockOpenShift(tx, outletID, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	if refund.PaymentMethod == models.PaymentCash {
+		needsShift, err := cashNeedsShift(tx, outletID, refund.ShiftID)
+		if err != nil {
+			return nil, err
+		}
+		if needsShift {
+			return nil, models.NewConflictError("this outlet uses shifts, open a shift before paying back cash")
+		}
+	}
+
+	err = tx.QueryRow(
+		`INSERT INTO transactions (outlet_id, shift_id, customer_id, refund_of_id, refund_reason, gross_amount, discount_amount, subtotal_amount, service_amount,
+			tax_amount, rounding_amount, total_amount, payment_method, amount_tendered, change_amount, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, 0, $15) RETURNING id, created_at`,
+		outletID, refund.ShiftID, refund.CustomerID, transactionID, req.Reason, refund.GrossAmount, refund.DiscountAmount, refund.SubtotalAmount, refund.ServiceAmount,
+		refund.TaxAmount, refund.RoundingAmount, refund.TotalAmount, refund.PaymentMethod, refund.AmountTendered, createdBy,
+	).Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(
+		"INSERT INTO transaction_payments (transaction_id, method, amount, change_amount) VALUES ($1, $2, $3, 0) RETURNING id",
+		refund.ID, refund.PaymentMethod, refund.TotalAmount,
+	).Scan(&refund.Payments[0].ID)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := models.StockRefTransaction
+	for i, detail := range details {
+		// barang rusak tidak dikembalikan ke stok
+		if !detail.Damaged {
+			err := adjustStock(tx, &models.StockMovement{
+				ProductID:     detail.ProductID,
+				OutletID:      outletID,
+				Delta:         -detail.Quantity,
+				Reason:        models.StockReasonRefund,
+				ReferenceType: &reference,
+				ReferenceID:   &refund.ID,
+				CreatedBy:     createdBy,
+				Note:          req.Reason,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		details[i].TransactionID = refund.ID
+		err = tx.QueryRow(
+			`INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal, discount_amount,
+				tax_profile_id, tax_name, tax_rate, tax_inclusive, service_amount, taxable_amount, tax_amount, refunded_detail_id, damaged)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id`,
+			refund.ID, detail.ProductID, detail.Quantity, detail.Subtotal, detail.Discount,
+			detail.TaxProfileID, detail.TaxName, detail.TaxRate, detail.TaxInclusive, detail.Service, detail.TaxableAmount, detail.Tax,
+			detail.RefundedDetailID, detail.Damaged,
+		).Scan(&details[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// poin dari penjualan ditarik sebanding dengan yang direfund
+	if customerID != nil {
+		if err := reverseRefundPoints(tx, transactionID, refund.ID, *customerID, pointsExpiry, createdBy); err != nil {
+			return nil, err
+		}
+		if err := loadTransactionPoints(tx, &refund); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+// VoidTransaction cancels a sale: stock of every line is put back, coupon
+// uses are released and the transaction is marked voided. window is how
+// long after the sale a void is allowed; zero means until the end of the
+// business day.
+func (repo *TransactionRepository) VoidTransaction(id int, reason string, voidedBy *string, window, pointsExpiry time.Duration) (*models.Transaction, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var outletID int
+	var refundOfID, customerID *int
+	var voided, sameDay, hasRefunds bool
+	var age float64
+	err = tx.QueryRow(`
+		SELECT outlet_id, refund_of_id, customer_id, voided_at IS NOT NULL, DATE(created_at) = CURRENT_DATE,
+			EXTRACT(EPOCH FROM NOW() - created_at)::float8,
+			EXISTS (SELECT 1 FROM transactions r WHERE r.refund_of_id = t.id)
+		FROM transactions t
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&outletID, &refundOfID, &customerID, &voided, &sameDay, &age, &hasRefunds)
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("transaction not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case voided:
+		return nil, models.NewConflictError("transaction %d is already voided", id)
+	case refundOfID != nil:
+		return nil, models.NewValidationError("transaction %d is a refund and cannot be voided", id)
+	case hasRefunds:
+		return nil, models.NewConflictError("transaction %d has refunds and cannot be voided", id)
+	case window <= 0 && !sameDay:
+		return nil, models.NewValidationError("transaction %d can only be voided on the day of the sale", id)
+	case window > 0 && age > window.Seconds():
+		return nil, models.NewValidationError("transaction %d can only be voided within %s of the sale", id, window)
+	}
+	if customerID != nil {
+		if err := lockCustomer(tx, *customerID); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := tx.Query("SELECT product_id, quantity FROM transaction_details WHERE transaction_id = $1 ORDER BY product_id", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type soldItem struct{ productID, quantity int }
+	items := make([]soldItem, 0)
+	for rows.Next() {
+		var item soldItem
+		if err := rows.Scan(&item.productID, &item.quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// kembalikan stok yang terjual, berurutan per product id
+	reference := models.StockRefTransaction
+	for _, item := range items {
+		err := adjustStock(tx, &models.StockMovement{
+			ProductID:     item.productID,
+			OutletID:      outletID,
+			Delta:         item.quantity,
+			Reason:        models.StockReasonVoid,
+			ReferenceType: &reference,
+			ReferenceID:   &id,
+			CreatedBy:     voidedBy,
+			Note:          reason,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// kuota kupon yang terpakai dikembalikan
+	_, err = tx.Exec(`
+		UPDATE promotions SET usage_count = usage_count - 1
+		WHERE usage_count > 0 AND coupon_code IS NOT NULL
+			AND id IN (SELECT promotion_id FROM transaction_discounts WHERE transaction_id = $1)
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// poin yang didapat ditarik, poin yang ditukar dikembalikan
+	if customerID != nil {
+		if err := reverseVoidPoints(tx, id, *customerID, pointsExpiry, voidedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	// uang tunai yang dikembalikan keluar dari laci shift yang sedang buka,
+	// bukan dari shift penjualan yang mungkin sudah ditutup
+	voidShiftID, err := lockOpenShift(tx, outletID, voidedBy)
+	if err != nil {
+		return nil, err
+	}
+	var paidCash bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM transaction_payments WHERE transaction_id = $1 AND method = $2)", id, models.PaymentCash).Scan(&paidCash)
+	if err != nil {
+		return nil, err
+	}
+	if paidCash {
+		needsShift, err := cashNeedsShift(tx, outletID, voidShiftID)
+		if err != nil {
+			return nil, err
+		}
+		if needsShift {
+			return nil, models.NewConflictError("this outlet uses shifts, open a shift before paying back cash")
+		}
+	}
+
+	_, err = tx.Exec("UPDATE transactions SET voided_at = NOW(), voided_by = $1, void_reason = $2, void_shift_id = $3 WHERE id = $4", voidedBy, reason, voidShiftID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := loadTransaction(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// MarkPrinted counts a printed receipt of the transaction and returns how
+// many times it was printed before. The count is read and incremented in one
+// statement so concurrent prints each see a different count.
+func (repo *TransactionRepository) MarkPrinted(id int) (int, error) {
+	var printed int
+	err := repo.db.QueryRow("UPDATE transactions SET print_count = print_count + 1 WHERE id = $1 RETURNING print_count - 1", id).Scan(&printed)
+	if err == sql.ErrNoRows {
+		return 0, models.NewNotFoundError("transaction not found")
+	}
+	return printed, err
+}
+
+// UnmarkPrinted takes back a print counted by MarkPrinted that never
+// reached the printer
+func (repo *TransactionRepository) UnmarkPrinted(id int) error {
+	_, err := repo.db.Exec("UPDATE transactions SET print_count = print_count - 1 WHERE id = $1 AND print_count > 0", id)
+	return err
+}
+
+// transactionStatus derives the status of transactions aliased as t
+const transactionStatus = `CASE
+			WHEN t.voided_at IS NOT NULL THEN 'voided'
+			WHEN t.refund_of_id IS NOT NULL THEN 'refund'
+			WHEN EXISTS (SELECT 1 FROM transactions r WHERE r.refund_of_id = t.id) THEN 'refunded'
+			ELSE 'completed'
+		END`
+
+// GetByID - get a transaction with its lines, payments and discounts
+func (repo *TransactionRepository) GetByID(id int) (*models.Transaction, error) {
+	return loadTransaction(repo.db, id)
+}
+
+// GetAll - list transaction headers matching the filter, newest first,
+// returns the page and total count
+func (repo *TransactionRepository) GetAll(filter models.TransactionFilter) ([]models.TransactionSummary, int, error) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	addArg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.StartDate != "" {
+		conditions = append(conditions, "DATE(t.created_at) >= "+addArg(filter.StartDate))
+	}
+	if filter.EndDate != "" {
+		conditions = append(conditions, "DATE(t.created_at) <= "+addArg(filter.EndDate))
+	}
+	if filter.MinTotal != nil {
+		conditions = append(conditions, "t.total_amount >= "+addArg(*filter.MinTotal))
+	}
+	if filter.MaxTotal != nil {
+		conditions = append(conditions, "t.total_amount <= "+addArg(*filter.MaxTotal))
+	}
+	if filter.ProductID != nil {
+		// varian dari produk tersebut ikut dicari
+		arg := addArg(*filter.ProductID)
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM transaction_details td
+			JOIN products p ON p.id = td.product_id
+			WHERE td.transaction_id = t.id AND (p.id = `+arg+` OR p.parent_id = `+arg+`)
+		)`)
+	}
+	if filter.OutletID != nil {
+		conditions = append(conditions, "t.outlet_id = "+addArg(*filter.OutletID))
+	}
+	if filter.ShiftID != nil {
+		conditions = append(conditions, "t.shift_id = "+addArg(*filter.ShiftID))
+	}
+	if filter.CustomerID != nil {
+		conditions = append(conditions, "t.customer_id = "+addArg(*filter.CustomerID))
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, transactionStatus+" = "+addArg(filter.Status))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := repo.db.QueryRow("SELECT COUNT(*) FROM transactions t "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT t.id, t.outlet_id, t.customer_id, ` + transactionStatus + `, t.refund_of_id, t.gross_amount, t.discount_amount, t.tax_amount,
+			t.total_amount, t.payment_method,
+			COALESCE((SELECT SUM(td.quantity) FROM transaction_details td WHERE td.transaction_id = t.id), 0),
+			t.created_by, t.created_at
+		FROM transactions t
+		` + where + `
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT ` + addArg(filter.Limit) + ` OFFSET ` + addArg(filter.Offset)
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	transactions := make([]models.TransactionSummary, 0)
+	for rows.Next() {
+		var t models.TransactionSummary
+		err := rows.Scan(&t.ID, &t.OutletID, &t.CustomerID, &t.Status, &t.RefundOfID, &t.GrossAmount, &t.DiscountAmount, &t.TaxAmount,
+			&t.TotalAmount, &t.PaymentMethod, &t.ItemCount, &t.CreatedBy, &t.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		transactions = append(transactions, t)
+	}
+
+	return transactions, total, rows.Err()
+}
+
+// loadTransaction reads a transaction with its lines, payments and discounts
+func loadTransaction(db dbtx, id int) (*models.Transaction, error) {
+	var t models.Transaction
+	var buyerName, buyerAddress, buyerNPWP *string
+	err := db.QueryRow(`
+		SELECT t.id, t.outlet_id, t.shift_id, t.customer_id, `+transactionStatus+`, t.refund_of_id, COALESCE(t.refund_reason, ''), t.created_by,
+			t.voided_at, t.voided_by, COALESCE(t.void_reason, ''),
+			t.gross_amount, t.discount_amount, t.subtotal_amount, t.service_amount, t.tax_amount, t.rounding_amount, t.total_amount,
+			t.coupon_code, t.points_discount, t.payment_method, t.amount_tendered, t.change_amount, t.print_count, t.created_at,
+			t.buyer_name, t.buyer_address, t.buyer_npwp
+		FROM transactions t
+		WHERE t.id = $1
+	`, id).Scan(&t.ID, &t.OutletID, &t.ShiftID, &t.CustomerID, &t.Status, &t.RefundOfID, &t.RefundReason, &t.CreatedBy,
+		&t.VoidedAt, &t.VoidedBy, &t.VoidReason,
+		&t.GrossAmount, &t.DiscountAmount, &t.SubtotalAmount, &t.ServiceAmount, &t.TaxAmount, &t.RoundingAmount, &t.TotalAmount,
+		&t.CouponCode, &t.PointsDiscount, &t.PaymentMethod, &t.AmountTendered, &t.Change, &t.PrintCount, &t.CreatedAt,
+		&buyerName, &buyerAddress, &buyerNPWP)
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("transaction not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if buyerName != nil {
+		t.Buyer = &models.Buyer{Name: *buyerName}
+		if buyerAddress != nil {
+			t.Buyer.Address = *buyerAddress
+		}
+		if buyerNPWP != nil {
+			t.Buyer.NPWP = *buyerNPWP
+		}
+	}
+	if t.CustomerID != nil {
+		if err := loadTransactionPoints(db, &t); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := db.Query(`
+		SELECT td.id, td.transaction_id, td.product_id, p.name, td.quantity, td.subtotal, td.discount_amount,
+			td.tax_profile_id, td.tax_name, td.tax_rate, td.tax_inclusive, td.service_amount, td.taxable_amount, td.tax_amount,
+			td.refunded_detail_id, td.damaged
+		FROM transaction_details td
+		JOIN products p ON p.id = td.product_id
+		WHERE td.transaction_id = $1
+		ORDER BY td.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	t.Details = make([]models.TransactionDetail, 0)
+	for rows.Next() {
+		var d models.TransactionDetail
+		err := rows.Scan(&d.ID, &d.TransactionID, &d.ProductID, &d.ProductName, &d.Quantity, &d.Subtotal, &d.Discount,
+			&d.TaxProfileID, &d.TaxName, &d.TaxRate, &d.TaxInclusive, &d.Service, &d.TaxableAmount, &d.Tax,
+			&d.RefundedDetailID, &d.Damaged)
+		if err != nil {
+			return nil, err
+		}
+		t.Details = append(t.Details, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	t.Taxes = summarizeTaxes(t.Details)
+
+	rows, err = db.Query("SELECT id, method, amount, change_amount FROM transaction_payments WHERE transaction_id = $1 ORDER BY id", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	t.Payments = make([]models.TransactionPayment, 0)
+	for rows.Next() {
+		var p models.TransactionPayment
+		if err := rows.Scan(&p.ID, &p.Method, &p.Amount, &p.Change); err != nil {
+			return nil, err
+		}
+		t.Payments = append(t.Payments, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Query(`
+		SELECT promotion_id, name, transaction_detail_id, amount
+		FROM transaction_discounts
+		WHERE transaction_id = $1
+		ORDER BY id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	t.Discounts = make([]models.AppliedDiscount, 0)
+	for rows.Next() {
+		var d models.AppliedDiscount
+		if err := rows.Scan(&d.PromotionID, &d.Name, &d.DetailID, &d.Amount); err != nil {
+			return nil, err
+		}
+		t.Discounts = append(t.Discounts, d)
+	}
+
+	return &t, rows.Err()
+}
+
+// settlePayments checks that the tenders cover the total and gives the change
+// back from the cash tenders only. A single tender without an amount pays
+// the exact total.
+func settlePayments(total int, tenders []models.CheckoutPayment) ([]models.TransactionPayment, int, int, error) {
+	payments := make([]models.TransactionPayment, 0, len(tenders))
+	tendered, cash := 0, 0
+	for _, tender := range tenders {
+		amount := total
+		if tender.Amount != nil {
+			amount = *tender.Amount
+		}
+		payments = append(payments, models.TransactionPayment{Method: tender.Method, Amount: amount})
+		tendered += amount
+		if tender.Method == models.PaymentCash {
+			cash += amount
+		}
+	}
+
+	if tendered < total {
+		return nil, 0, 0, models.NewValidationError("payments of %d do not cover the total %d", tendered, total)
+	}
+	change := tendered - total
+	if change > cash {
+		return nil, 0, 0, models.NewValidationError("non-cash payments exceed the total %d, only cash can be given change", total)
+	}
+
+	remaining := change
+	for i := range payments {
+		if remaining == 0 {
+			break
+		}
+		if payments[i].Method != models.PaymentCash {
+			continue
+		}
+		payments[i].Change = min(remaining, payments[i].Amount)
+		remaining -= payments[i].Change
+	}
+
+	return payments, tendered, change, nil
+}
+
+// getPaymentSummary - revenue per payment method, net of change, for the
+// transactions matching the condition on t
+func (repo *TransactionRepository) getPaymentSummary(condition string, args ...any) ([]models.PaymentSummary, error) {
+	rows, err := repo.db.Query(`
+		SELECT tp.method, COALESCE(SUM(tp.amount - tp.change_amount), 0), COUNT(DISTINCT t.id)
+		FROM transaction_payments tp
+		JOIN transactions t ON t.id = tp.transaction_id
+		WHERE `+condition+`
+		GROUP BY tp.method
+		ORDER BY tp.method
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := make([]models.PaymentSummary, 0)
+	for rows.Next() {
+		var p models.PaymentSummary
+		if err := rows.Scan(&p.Method, &p.Amount, &p.TotalTransaction); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
+
+// GetTodaySalesSummary - summary for today, outletID nil means all outlets
+func (repo *TransactionRepository) GetTodaySalesSummary(outletID *int) (*models.DailySalesSummary, error) {
 	// Get total revenue and transaction count for today
-	var totalRevenue, totalTransaction int
+	var summary models.DailySalesSummary
 	err := repo.db.QueryRow(`
-		SELECT COALESCE(SUM(total_amount), 0), COUNT(*)
+		SELECT COALESCE(SUM(gross_amount), 0), COALESCE(SUM(discount_amount), 0), COALESCE(SUM(subtotal_amount), 0),
+			COALESCE(SUM(service_amount), 0), COALESCE(SUM(tax_amount), 0), COALESCE(SUM(rounding_amount), 0),
+			COALESCE(SUM(total_amount), 0), COUNT(*) FILTER (WHERE refund_of_id IS NULL),
+			COALESCE(-SUM(total_amount) FILTER (WHERE refund_of_id IS NOT NULL), 0), COUNT(*) FILTER (WHERE refund_of_id IS NOT NULL)
 		FROM transactions
 		WHERE DATE(created_at) = CURRENT_DATE
-	`).Scan(&totalRevenue, &totalTransaction)
+			AND voided_at IS NULL
+			AND ($1::int IS NULL OR outlet_id = $1)
+	`, outletID).Scan(&summary.GrossSales, &summary.TotalDiscount, &summary.NetSales,
+		&summary.TotalServiceCharge, &summary.TotalTax, &summary.TotalRounding, &summary.TotalRevenue, &summary.TotalTransaction,
+		&summary.TotalRefund, &summary.RefundCount)
 
 	if err != nil {
 		return nil, err
 	}
 
-	// Get most selling product today
+	// Get most selling product today, variants are counted under their parent
 	var mostSelling models.MostSellingProduct
 	var name sql.NullString
 	var quantity sql.NullInt64
@@ -114,12 +1193,15 @@ func (repo *TransactionRepository) GetTodaySalesSummary() (*models.DailySalesSum
 		SELECT p.name, COALESCE(SUM(td.quantity), 0) as total_qty
 		FROM transaction_details td
 		JOIN transactions t ON td.transaction_id = t.id
-		JOIN products p ON td.product_id = p.id
+		JOIN products v ON td.product_id = v.id
+		JOIN products p ON p.id = COALESCE(v.parent_id, v.id)
 		WHERE DATE(t.created_at) = CURRENT_DATE
+			AND t.voided_at IS NULL
+			AND ($1::int IS NULL OR t.outlet_id = $1)
 		GROUP BY p.id, p.name
 		ORDER BY total_qty DESC
 		LIMIT 1
-	`).Scan(&name, &quantity)
+	`, outletID).Scan(&name, &quantity)
 
 	if name.Valid {
 		mostSelling.Name = name.String
@@ -132,27 +1214,39 @@ func (repo *TransactionRepository) GetTodaySalesSummary() (*models.DailySalesSum
 		return nil, err
 	}
 
-	return &models.DailySalesSummary{
-		TotalTransaction:   totalTransaction,
-		TotalRevenue:       totalRevenue,
-		MostSellingProduct: mostSelling,
-	}, nil
+	payments, err := repo.getPaymentSummary("t.voided_at IS NULL AND DATE(t.created_at) = CURRENT_DATE AND ($1::int IS NULL OR t.outlet_id = $1)", outletID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.MostSellingProduct = mostSelling
+	summary.Payments = payments
+
+	return &summary, nil
 }
 
-func (repo *TransactionRepository) GetSalesSummaryByDateRange(startDate, endDate string) (*models.DailySalesSummary, error) {
+// GetSalesSummaryByDateRange - summary between two dates, outletID nil means all outlets
+func (repo *TransactionRepository) GetSalesSummaryByDateRange(startDate, endDate string, outletID *int) (*models.DailySalesSummary, error) {
 	// Get total revenue and transaction count for date range
-	var totalRevenue, totalTransaction int
+	var summary models.DailySalesSummary
 	err := repo.db.QueryRow(`
-		SELECT COALESCE(SUM(total_amount), 0), COUNT(*)
+		SELECT COALESCE(SUM(gross_amount), 0), COALESCE(SUM(discount_amount), 0), COALESCE(SUM(subtotal_amount), 0),
+			COALESCE(SUM(service_amount), 0), COALESCE(SUM(tax_amount), 0), COALESCE(SUM(rounding_amount), 0),
+			COALESCE(SUM(total_amount), 0), COUNT(*) FILTER (WHERE refund_of_id IS NULL),
+			COALESCE(-SUM(total_amount) FILTER (WHERE refund_of_id IS NOT NULL), 0), COUNT(*) FILTER (WHERE refund_of_id IS NOT NULL)
 		FROM transactions
 		WHERE DATE(created_at) BETWEEN $1 AND $2
-	`, startDate, endDate).Scan(&totalRevenue, &totalTransaction)
+			AND voided_at IS NULL
+			AND ($3::int IS NULL OR outlet_id = $3)
+	`, startDate, endDate, outletID).Scan(&summary.GrossSales, &summary.TotalDiscount, &summary.NetSales,
+		&summary.TotalServiceCharge, &summary.TotalTax, &summary.TotalRounding, &summary.TotalRevenue, &summary.TotalTransaction,
+		&summary.TotalRefund, &summary.RefundCount)
 
 	if err != nil {
 		return nil, err
 	}
 
-	// Get most selling product in date range
+	// Get most selling product in date range, variants are counted under their parent
 	var mostSelling models.MostSellingProduct
 	var name sql.NullString
 	var quantity sql.NullInt64
@@ -161,12 +1255,15 @@ func (repo *TransactionRepository) GetSalesSummaryByDateRange(startDate, endDate
 		SELECT p.name, COALESCE(SUM(td.quantity), 0) as total_qty
 		FROM transaction_details td
 		JOIN transactions t ON td.transaction_id = t.id
-		JOIN products p ON td.product_id = p.id
+		JOIN products v ON td.product_id = v.id
+		JOIN products p ON p.id = COALESCE(v.parent_id, v.id)
 		WHERE DATE(t.created_at) BETWEEN $1 AND $2
+			AND t.voided_at IS NULL
+			AND ($3::int IS NULL OR t.outlet_id = $3)
 		GROUP BY p.id, p.name
 		ORDER BY total_qty DESC
 		LIMIT 1
-	`, startDate, endDate).Scan(&name, &quantity)
+	`, startDate, endDate, outletID).Scan(&name, &quantity)
 
 	if name.Valid {
 		mostSelling.Name = name.String
@@ -179,9 +1276,127 @@ func (repo *TransactionRepository) GetSalesSummaryByDateRange(startDate, endDate
 		return nil, err
 	}
 
-	return &models.DailySalesSummary{
-		TotalTransaction:   totalTransaction,
-		TotalRevenue:       totalRevenue,
-		MostSellingProduct: mostSelling,
-	}, nil
+	payments, err := repo.getPaymentSummary("t.voided_at IS NULL AND DATE(t.created_at) BETWEEN $1 AND $2 AND ($3::int IS NULL OR t.outlet_id = $3)", startDate, endDate, outletID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.MostSellingProduct = mostSelling
+	summary.Payments = payments
+
+	return &summary, nil
+}
+
+// GetProductSales - quantity and revenue per product between two dates,
+// variants rolled up under their parent with a per-variant breakdown
+func (repo *TransactionRepository) GetProductSales(startDate, endDate string, outletID *int) ([]models.ProductSales, error) {
+	rows, err := repo.db.Query(`
+		SELECT COALESCE(v.parent_id, v.id), COALESCE(p.name, v.name), v.id, v.name, v.option_values,
+			SUM(td.quantity), SUM(td.subtotal), SUM(td.discount_amount)
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		JOIN products v ON td.product_id = v.id
+		LEFT JOIN products p ON p.id = v.parent_id
+		WHERE DATE(t.created_at) BETWEEN $1 AND $2
+			AND t.voided_at IS NULL
+			AND ($3::int IS NULL OR t.outlet_id = $3)
+		GROUP BY v.id, v.name, v.parent_id, p.name, v.option_values
+		ORDER BY COALESCE(v.parent_id, v.id), v.id
+	`, startDate, endDate, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sales := make([]models.ProductSales, 0)
+	for rows.Next() {
+		var productID int
+		var name string
+		var variant models.VariantSales
+		var optionValues *string
+		err := rows.Scan(&productID, &name, &variant.ProductID, &variant.Name, &optionValues, &variant.Quantity, &variant.Gross, &variant.Discount)
+		if err != nil {
+			return nil, err
+		}
+		variant.Revenue = variant.Gross - variant.Discount
+
+		n := len(sales)
+		if n == 0 || sales[n-1].ProductID != productID {
+			sales = append(sales, models.ProductSales{ProductID: productID, Name: name})
+			n++
+		}
+		row := &sales[n-1]
+		row.Quantity += variant.Quantity
+		row.Gross += variant.Gross
+		row.Discount += variant.Discount
+		row.Revenue += variant.Revenue
+
+		// produk tanpa varian tidak punya rincian
+		if variant.ProductID == productID {
+			continue
+		}
+		if optionValues != nil {
+			if err := json.Unmarshal([]byte(*optionValues), &variant.OptionValues); err != nil {
+				return nil, err
+			}
+		}
+		row.Variants = append(row.Variants, variant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// urutkan dari yang paling laku
+	sort.SliceStable(sales, func(i, j int) bool { return sales[i].Quantity > sales[j].Quantity })
+
+	return sales, nil
+}
+
+// GetTaxReport - tax collected per month and tax profile between two dates,
+// for filing. The rate and inclusive flag are the ones at the time of sale.
+func (repo *TransactionRepository) GetTaxReport(startDate, endDate string, outletID *int) (*models.TaxReport, error) {
+	rows, err := repo.db.Query(`
+		SELECT TO_CHAR(t.created_at, 'YYYY-MM'), td.tax_profile_id, td.tax_name, td.tax_rate, td.tax_inclusive,
+			SUM(td.taxable_amount), SUM(td.tax_amount), COUNT(DISTINCT t.id)
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		WHERE td.tax_profile_id IS NOT NULL
+			AND DATE(t.created_at) BETWEEN $1 AND $2
+			AND t.voided_at IS NULL
+			AND ($3::int IS NULL OR t.outlet_id = $3)
+		GROUP BY 1, td.tax_profile_id, td.tax_name, td.tax_rate, td.tax_inclusive
+		ORDER BY 1, td.tax_profile_id, td.tax_rate
+	`, startDate, endDate, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := models.TaxReport{StartDate: startDate, EndDate: endDate, Taxes: make([]models.TaxReportRow, 0)}
+	for rows.Next() {
+		var row models.TaxReportRow
+		err := rows.Scan(&row.Period, &row.TaxProfileID, &row.Name, &row.Rate, &row.Inclusive,
+			&row.TaxableAmount, &row.TaxAmount, &row.TotalTransaction)
+		if err != nil {
+			return nil, err
+		}
+		report.TotalTax += row.TaxAmount
+		report.Taxes = append(report.Taxes, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = repo.db.QueryRow(`
+		SELECT COALESCE(SUM(service_amount), 0)
+		FROM transactions
+		WHERE DATE(created_at) BETWEEN $1 AND $2
+			AND voided_at IS NULL
+			AND ($3::int IS NULL OR outlet_id = $3)
+	`, startDate, endDate, outletID).Scan(&report.TotalServiceCharge)
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
 }
@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// openTestDB applies every migration to a fresh schema of the database in
+// TEST_DB_CONN and returns a pool bound to that schema. The test is skipped
+// when TEST_DB_CONN is not set.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	conn := os.Getenv("TEST_DB_CONN")
+	if conn == "" {
+		t.Skip("TEST_DB_CONN not set")
+	}
+
+	admin, err := sql.Open("pgx", conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("kasir_test_%d", os.Getpid())
+	if _, err := admin.Exec("DROP SCHEMA IF EXISTS " + schema + " CASCADE; CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA IF EXISTS " + schema + " CASCADE") })
+
+	// setiap koneksi di pool memakai schema test
+	if strings.Contains(conn, "://") {
+		separator := "?"
+		if strings.Contains(conn, "?") {
+			separator = "&"
+		}
+		conn += separator + "search_path=" + schema
+	} else {
+		conn += " search_path=" + schema
+	}
+	db, err := sql.Open("pgx", conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// nama file migration menentukan urutannya
+	files, err := filepath.Glob(filepath.Join("..", "migrations", "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		migration, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(string(migration)); err != nil {
+			t.Fatalf("%s: %v", filepath.Base(file), err)
+		}
+	}
+
+	return db
+}
+
+func TestCreateTransactionLastUnitInParallel(t *testing.T) {
+	db := openTestDB(t)
+
+	var productID int
+	err := db.QueryRow("INSERT INTO products (name, price, stock) VALUES ('Kopi Susu', 18000, 1) RETURNING id").Scan(&productID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO product_stocks (product_id, outlet_id, stock) VALUES ($1, 1, 1)", productID); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewTransactionRepository(db)
+	req := models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: productID, Quantity: 1}},
+		Payments: []models.CheckoutPayment{{Method: models.PaymentQRIS}},
+	}
+
+	const checkouts = 8
+	errs := make([]error, checkouts)
+	var wg sync.WaitGroup
+	for i := range checkouts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = repo.CreateTransaction(1, req, nil, nil, models.LoyaltyConfig{})
+		}()
+	}
+	wg.Wait()
+
+	sold := 0
+	for _, err := range errs {
+		var stockErr *models.InsufficientStockError
+		switch {
+		case err == nil:
+			sold++
+		case errors.As(err, &stockErr):
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if sold != 1 {
+		t.Errorf("sold the last unit %d times, want once", sold)
+	}
+
+	var stock, outletStock int
+	err = db.QueryRow("SELECT p.stock, ps.stock FROM products p JOIN product_stocks ps ON ps.product_id = p.id AND ps.outlet_id = 1 WHERE p.id = $1", productID).
+		Scan(&stock, &outletStock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stock != 0 || outletStock != 0 {
+		t.Errorf("stock after checkouts = %d, outlet stock = %d, want 0 and 0", stock, outletStock)
+	}
+}
+
+func TestSettlePayments(t *testing.T) {
+	amount := func(v int) *int { return &v }
+	cash := func(v *int) models.CheckoutPayment {
+		return models.CheckoutPayment{Method: models.PaymentCash, Amount: v}
+	}
+	qris := func(v *int) models.CheckoutPayment {
+		return models.CheckoutPayment{Method: models.PaymentQRIS, Amount: v}
+	}
+
+	tests := []struct {
+		name         string
+		total        int
+		tenders      []models.CheckoutPayment
+		wantPayments []models.TransactionPayment
+		wantTendered int
+		wantChange   int
+		wantErr      bool
+	}{
+		{
+			name:         "exact total without an amount",
+			total:        87500,
+			tenders:      []models.CheckoutPayment{cash(nil)},
+			wantPayments: []models.TransactionPayment{{Method: models.PaymentCash, Amount: 87500}},
+			wantTendered: 87500,
+		},
+		{
+			name:         "cash gives change",
+			total:        87500,
+			tenders:      []models.CheckoutPayment{cash(amount(100000))},
+			wantPayments: []models.TransactionPayment{{Method: models.PaymentCash, Amount: 100000, Change: 12500}},
+			wantTendered: 100000,
+			wantChange:   12500,
+		},
+		{
+			name:         "split tender, change from the cash part",
+			total:        87500,
+			tenders:      []models.CheckoutPayment{qris(amount(50000)), cash(amount(50000))},
+			wantPayments: []models.TransactionPayment{{Method: models.PaymentQRIS, Amount: 50000}, {Method: models.PaymentCash, Amount: 50000, Change: 12500}},
+			wantTendered: 100000,
+			wantChange:   12500,
+		},
+		{
+			name:         "change is taken from cash tenders in order",
+			total:        10000,
+			tenders:      []models.CheckoutPayment{cash(amount(5000)), cash(amount(20000))},
+			wantPayments: []models.TransactionPayment{{Method: models.PaymentCash, Amount: 5000, Change: 5000}, {Method: models.PaymentCash, Amount: 20000, Change: 10000}},
+			wantTendered: 25000,
+			wantChange:   15000,
+		},
+		{
+			name:         "change exactly equal to the cash part",
+			total:        50000,
+			tenders:      []models.CheckoutPayment{qris(amount(50000)), cash(amount(10000))},
+			wantPayments: []models.TransactionPayment{{Method: models.PaymentQRIS, Amount: 50000}, {Method: models.PaymentCash, Amount: 10000, Change: 10000}},
+			wantTendered: 60000,
+			wantChange:   10000,
+		},
+		{
+			name:         "zero total",
+			total:        0,
+			tenders:      []models.CheckoutPayment{cash(nil)},
+			wantPayments: []models.TransactionPayment{{Method: models.PaymentCash, Amount: 0}},
+		},
+		{
+			name:    "one rupiah short",
+			total:   87500,
+			tenders: []models.CheckoutPayment{cash(amount(87499))},
+			wantErr: true,
+		},
+		{
+			name:    "non-cash cannot be given change",
+			total:   87500,
+			tenders: []models.CheckoutPayment{qris(amount(100000))},
+			wantErr: true,
+		},
+		{
+			name:    "change larger than the cash part",
+			total:   87500,
+			tenders: []models.CheckoutPayment{qris(amount(90000)), cash(amount(5000))},
+			wantErr: true,
+		},
+		{
+			name:    "negative tender lowers what is paid",
+			total:   95000,
+			tenders: []models.CheckoutPayment{cash(amount(100000)), qris(amount(-10000))},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments, tendered, change, err := settlePayments(tt.total, tt.tenders)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("settlePayments succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(payments, tt.wantPayments) {
+				t.Errorf("payments = %+v, want %+v", payments, tt.wantPayments)
+			}
+			if tendered != tt.wantTendered || change != tt.wantChange {
+				t.Errorf("tendered, change = %d, %d, want %d, %d", tendered, change, tt.wantTendered, tt.wantChange)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+	"strings"
+	"time"
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// the lock is evaluated by the database so it uses the same clock as NOW().
+// Roles and permissions are comma separated, names cannot contain commas.
+const userColumns = `u.id, u.username, u.password_hash, u.active, COALESCE(u.locked_until > NOW(), false), u.locked_until, u.created_at,
+	(SELECT COALESCE(STRING_AGG(r.name, ',' ORDER BY r.name), '')
+		FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE ur.user_id = u.id),
+	(SELECT COALESCE(STRING_AGG(DISTINCT rp.permission, ',' ORDER BY rp.permission), '')
+		FROM user_roles ur JOIN role_permissions rp ON rp.role_id = ur.role_id WHERE ur.user_id = u.id)`
+
+func scanUser(row rowScanner) (*models.User, error) {
+	var u models.User
+	var roles, permissions string
+	err := row.Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Active, &u.Locked, &u.LockedUntil, &u.CreatedAt, &roles, &permissions)
+	if err != nil {
+		return nil, err
+	}
+	if !u.Locked {
+		u.LockedUntil = nil
+	}
+	u.Roles = splitList(roles)
+	u.Permissions = splitList(permissions)
+	return &u, nil
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return make([]string, 0)
+	}
+	return strings.Split(value, ",")
+}
+
+func (repo *UserRepository) GetAll() ([]models.User, error) {
+	rows, err := repo.db.Query("SELECT " + userColumns + " FROM users u ORDER BY u.username")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0)
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+
+	return users, rows.Err()
+}
+
+// GetByID - get user by ID
+func (repo *UserRepository) GetByID(id int) (*models.User, error) {
+	u, err := scanUser(repo.db.QueryRow("SELECT "+userColumns+" FROM users u WHERE u.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("user not found")
+	}
+	return u, err
+}
+
+// GetByUsername - usernames are matched case-insensitively
+func (repo *UserRepository) GetByUsername(username string) (*models.User, error) {
+	u, err := scanUser(repo.db.QueryRow("SELECT "+userColumns+" FROM users u WHERE LOWER(u.username) = LOWER($1)", username))
+	if err == sql.ErrNoRows {
+		return nil, models.NewNotFoundError("user not found")
+	}
+	return u, err
+}
+
+func (repo *UserRepository) Count() (int, error) {
+	var count int
+	err := repo.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+	return count, err
+}
+
+// Create inserts the user with user.Roles
+func (repo *UserRepository) Create(user *models.User) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"INSERT INTO users (username, password_hash, active) VALUES ($1, $2, $3) RETURNING id, created_at",
+		user.Username, user.PasswordHash, user.Active,
+	).Scan(&user.ID, &user.CreatedAt)
+	if isUniqueViolation(err) {
+		return models.NewConflictError("username %s is already taken", user.Username)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := assignRoles(tx, user.ID, user.Roles); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// permission dibaca ulang dari role
+	created, err := repo.GetByID(user.ID)
+	if err != nil {
+		return err
+	}
+	*user = *created
+	return nil
+}
+
+// SetRoles replaces the roles of a user
+func (repo *UserRepository) SetRoles(userID int, roles []string) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow("SELECT id FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("user not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM user_roles WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	if err := assignRoles(tx, userID, roles); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func assignRoles(tx *sql.Tx, userID int, roles []string) error {
+	for _, name := range roles {
+		result, err := tx.Exec("INSERT INTO user_roles (user_id, role_id) SELECT $1, id FROM roles WHERE name = $2", userID, name)
+		if err != nil {
+			return err
+		}
+		count, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return models.NewValidationError("role %s not found", name)
+		}
+	}
+	return nil
+}
+
+// Update saves the password hash and active flag, the username never changes
+func (repo *UserRepository) Update(user *models.User) error {
+	err := repo.db.QueryRow(
+		"UPDATE users SET password_hash = $1, active = $2 WHERE id = $3 RETURNING created_at",
+		user.PasswordHash, user.Active, user.ID,
+	).Scan(&user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return models.NewNotFoundError("user not found")
+	}
+	return err
+}
+
+// RecordFailedLogin counts a failed login. When the count reaches
+// maxAttempts the user is locked for lockout and the count starts over.
+// It returns whether the user is locked now.
+func (repo *UserRepository) RecordFailedLogin(id, maxAttempts int, lockout time.Duration) (bool, error) {
+	var locked bool
+	err := repo.db.QueryRow(`
+		UPDATE users
+		SET failed_logins = CASE WHEN failed_logins + 1 >= $2 THEN 0 ELSE failed_logins + 1 END,
+			locked_until = CASE WHEN failed_logins + 1 >= $2 THEN NOW() + $3 * INTERVAL '1 second' ELSE locked_until END
+		WHERE id = $1
+		RETURNING COALESCE(locked_until > NOW(), false)
+	`, id, maxAttempts, lockout.Seconds()).Scan(&locked)
+	return locked, err
+}
+
+// ResetFailedLogins clears the count after a successful login
+func (repo *UserRepository) ResetFailedLogins(id int) error {
+	_, err := repo.db.Exec("UPDATE users SET failed_logins = 0, locked_until = NULL WHERE id = $1 AND (failed_logins > 0 OR locked_until IS NOT NULL)", id)
+	return err
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AuthConfig holds the token signing key and lifetimes and the login
+// lockout policy
+type AuthConfig struct {
+	Secret      []byte
+	AccessTTL   time.Duration
+	RefreshTTL  time.Duration
+	MaxAttempts int           // failed logins before the user is locked
+	Lockout     time.Duration // how long a locked user has to wait
+}
+
+type AuthService struct {
+	users  *repositories.UserRepository
+	config AuthConfig
+}
+
+func NewAuthService(users *repositories.UserRepository, config AuthConfig) *AuthService {
+	return &AuthService{users: users, config: config}
+}
+
+// Token types, an access token cannot be used to refresh and the other way
+// around
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+type tokenClaims struct {
+	Username  string `json:"username"`
+	TokenType string `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// dummyHash is compared against when the username does not exist, so an
+// unknown user takes as long to reject as a wrong password
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("kasir-api-dummy-password"), bcrypt.DefaultCost)
+
+func (s *AuthService) Login(req models.LoginRequest) (*models.TokenResponse, error) {
+	invalid := models.NewUnauthorizedError("invalid username or password")
+
+	username := strings.TrimSpace(req.Username)
+	if username == "" || req.Password == "" {
+		return nil, models.NewValidationError("username and password are required")
+	}
+
+	user, err := s.users.GetByUsername(username)
+	var notFound *models.NotFoundError
+	if errors.As(err, &notFound) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(req.Password))
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Locked {
+		return nil, models.NewUnauthorizedError("account is locked after too many failed logins, try again later")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		locked, err := s.users.RecordFailedLogin(user.ID, s.config.MaxAttempts, s.config.Lockout)
+		if err != nil {
+			return nil, err
+		}
+		if locked {
+			return nil, models.NewUnauthorizedError("account is locked after too many failed logins, try again later")
+		}
+		return nil, invalid
+	}
+	if !user.Active {
+		return nil, models.NewUnauthorizedError("account is disabled")
+	}
+
+	if err := s.users.ResetFailedLogins(user.ID); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(user)
+}
+
+// Refresh exchanges a refresh token for a new pair of tokens
+func (s *AuthService) Refresh(req models.RefreshRequest) (*models.TokenResponse, error) {
+	user, err := s.verify(req.RefreshToken, tokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(user)
+}
+
+// Authenticate returns the user of an access token. The user is read again
+// so disabling or locking an account takes effect immediately.
+func (s *AuthService) Authenticate(token string) (*models.User, error) {
+	return s.verify(token, tokenTypeAccess)
+}
+
+func (s *AuthService) verify(token, tokenType string) (*models.User, error) {
+	invalid := models.NewUnauthorizedError("invalid or expired token")
+
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return s.config.Secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || claims.TokenType != tokenType {
+		return nil, invalid
+	}
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return nil, invalid
+	}
+
+	user, err := s.users.GetByID(id)
+	var notFound *models.NotFoundError
+	if errors.As(err, &notFound) {
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.Active || user.Locked {
+		return nil, models.NewUnauthorizedError("account is disabled or locked")
+	}
+	return user, nil
+}
+
+func (s *AuthService) issueTokens(user *models.User) (*models.TokenResponse, error) {
+	access, err := s.sign(user, tokenTypeAccess, s.config.AccessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := s.sign(user, tokenTypeRefresh, s.config.RefreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenResponse{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.config.AccessTTL.Seconds()),
+		User:         *user,
+	}, nil
+}
+
+func (s *AuthService) sign(user *models.User, tokenType string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := tokenClaims{
+		Username:  user.Username,
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(user.ID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.config.Secret)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"kasir-api/models"
+	"strings"
+)
+
+const maxBarcodeLength = 64
+
+// normalizeBarcodes validates barcodes in place, inferring the type from the
+// code length when it is not given
+func normalizeBarcodes(barcodes []models.Barcode) error {
+	seen := make(map[string]bool, len(barcodes))
+	for i := range barcodes {
+		b := &barcodes[i]
+		b.Code = strings.TrimSpace(b.Code)
+		b.Type = strings.ToLower(strings.TrimSpace(b.Type))
+
+		if b.Code == "" {
+			return models.NewValidationError("barcodes[%d]: code is required", i)
+		}
+		if len(b.Code) > maxBarcodeLength || strings.ContainsAny(b.Code, " \t\r\n") {
+			return models.NewValidationError("barcodes[%d]: code must be at most %d characters without spaces", i, maxBarcodeLength)
+		}
+		if seen[b.Code] {
+			return models.NewValidationError("barcodes[%d]: duplicate code %s", i, b.Code)
+		}
+		seen[b.Code] = true
+
+		if b.Type == "" {
+			b.Type = inferBarcodeType(b.Code)
+		}
+
+		switch b.Type {
+		case models.BarcodeEAN13:
+			if len(b.Code) != 13 || !isValidGTIN(b.Code) {
+				return models.NewValidationError("barcodes[%d]: %s is not a valid EAN-13", i, b.Code)
+			}
+		case models.BarcodeUPCA:
+			if len(b.Code) != 12 || !isValidGTIN(b.Code) {
+				return models.NewValidationError("barcodes[%d]: %s is not a valid UPC-A", i, b.Code)
+			}
+		case models.BarcodeCustom:
+		default:
+			return models.NewValidationError("barcodes[%d]: type must be ean13, upca or custom", i)
+		}
+	}
+	return nil
+}
+
+// inferBarcodeType guesses the symbology from an all-digit code length
+func inferBarcodeType(code string) string {
+	if !isDigits(code) {
+		return models.BarcodeCustom
+	}
+	switch len(code) {
+	case 13:
+		return models.BarcodeEAN13
+	case 12:
+		return models.BarcodeUPCA
+	default:
+		return models.BarcodeCustom
+	}
+}
+
+// isValidGTIN checks the trailing mod-10 check digit used by EAN-13 and UPC-A.
+// Weights alternate 3,1,3,... starting from the digit left of the check digit.
+func isValidGTIN(code string) bool {
+	if len(code) < 2 || !isDigits(code) {
+		return false
+	}
+
+	sum := 0
+	weight := 3
+	for i := len(code) - 2; i >= 0; i-- {
+		sum += int(code[i]-'0') * weight
+		weight = 4 - weight
+	}
+
+	check := (10 - sum%10) % 10
+	return check == int(code[len(code)-1]-'0')
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
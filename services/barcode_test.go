@@ -0,0 +1,30 @@
+package services
+
+import "testing"
+
+func TestIsValidGTIN(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"4006381333931", true}, // EAN-13
+		{"8992761111113", true}, // EAN-13, prefix Indonesia
+		{"036000291452", true},  // UPC-A
+		{"96385074", true},      // EAN-8
+		{"00", true},            // check digit 0
+		{"4006381333932", false},
+		{"4006381333930", false},
+		{"036000291453", false},
+		{"10", false},
+		{"", false},
+		{"4", false},
+		{"400638133393A", false},
+		{"-400638133393", false},
+		{"4006381 33931", false},
+	}
+	for _, tt := range tests {
+		if got := isValidGTIN(tt.code); got != tt.want {
+			t.Errorf("isValidGTIN(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+type CategoryService struct {
+	repo *repositories.CategoryRepository
+}
+
+func NewCategoryService(repo *repositories.CategoryRepository) *CategoryService {
+	return &CategoryService{repo: repo}
+}
+
+func (s *CategoryService) GetAll() ([]models.Category, error) {
+	return s.repo.GetAll()
+}
+
+func (s *CategoryService) Create(category *models.Category) error {
+	if err := validateCategory(category); err != nil {
+		return err
+	}
+	return s.repo.Create(category)
+}
+
+func (s *CategoryService) GetByID(id int) (*models.Category, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *CategoryService) Update(category *models.Category) error {
+	if err := validateCategory(category); err != nil {
+		return err
+	}
+	return s.repo.Update(category)
+}
+
+func (s *CategoryService) Delete(id int, reassignTo *int) error {
+	return s.repo.Delete(id, reassignTo)
+}
+
+func validateCategory(category *models.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return models.NewValidationError("name is required")
+	}
+	if category.ParentID != nil && *category.ParentID == category.ID {
+		return models.NewValidationError("category cannot be its own parent")
+	}
+	if category.PointsMultiplier != nil && (*category.PointsMultiplier < 0 || *category.PointsMultiplier >= 1000) {
+		return models.NewValidationError("points_multiplier must be between 0 and 999.99")
+	}
+	return nil
+}
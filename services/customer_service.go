@@ -0,0 +1,181 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+type CustomerService struct {
+	repo         *repositories.CustomerRepository
+	transactions *repositories.TransactionRepository
+}
+
+func NewCustomerService(repo *repositories.CustomerRepository, transactions *repositories.TransactionRepository) *CustomerService {
+	return &CustomerService{repo: repo, transactions: transactions}
+}
+
+const (
+	defaultCustomerPageSize = 50
+	maxCustomerPageSize     = 200
+)
+
+func (s *CustomerService) GetAll(filter models.CustomerFilter) (*models.Page[models.Customer], error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultCustomerPageSize
+	}
+	if filter.Limit > maxCustomerPageSize {
+		filter.Limit = maxCustomerPageSize
+	}
+	if filter.Offset < 0 {
+		return nil, models.NewValidationError("offset must not be negative")
+	}
+
+	// pencarian dengan nomor hp dalam format +62 tetap ketemu
+	filter.Search = strings.TrimSpace(filter.Search)
+	if filter.Search != "" && strings.Trim(filter.Search, "+-() 0123456789") == "" {
+		filter.Search = normalizePhone(filter.Search)
+	}
+	if filter.Phone != "" {
+		filter.Phone = normalizePhone(filter.Phone)
+	}
+	filter.MemberCard = strings.TrimSpace(filter.MemberCard)
+
+	customers, total, err := s.repo.GetAll(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewPage(customers, total, filter.Limit, filter.Offset), nil
+}
+
+func (s *CustomerService) GetByID(id int) (*models.Customer, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *CustomerService) Create(customer *models.Customer) error {
+	if err := validateCustomer(customer); err != nil {
+		return err
+	}
+	return s.repo.Create(customer)
+}
+
+func (s *CustomerService) Update(customer *models.Customer) error {
+	if err := validateCustomer(customer); err != nil {
+		return err
+	}
+	return s.repo.Update(customer)
+}
+
+func (s *CustomerService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+// History returns the lifetime spend and visit count of a customer with a
+// page of their transactions, newest first
+func (s *CustomerService) History(id, limit, offset int) (*models.CustomerHistory, error) {
+	if limit <= 0 {
+		limit = defaultTransactionPageSize
+	}
+	if limit > maxTransactionPageSize {
+		limit = maxTransactionPageSize
+	}
+	if offset < 0 {
+		return nil, models.NewValidationError("offset must not be negative")
+	}
+
+	customer, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &models.CustomerHistory{Customer: *customer}
+	history.LifetimeSpend, history.VisitCount, history.LastVisitAt, err = s.repo.GetStats(id)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, total, err := s.transactions.GetAll(models.TransactionFilter{CustomerID: &id, Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	history.Transactions = models.NewPage(transactions, total, limit, offset)
+
+	return history, nil
+}
+
+// Points returns the points balance of a customer with a page of their
+// points ledger, newest first
+func (s *CustomerService) Points(id, limit, offset int) (*models.PointsAccount, error) {
+	if limit <= 0 {
+		limit = defaultCustomerPageSize
+	}
+	if limit > maxCustomerPageSize {
+		limit = maxCustomerPageSize
+	}
+	if offset < 0 {
+		return nil, models.NewValidationError("offset must not be negative")
+	}
+
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	balance, entries, total, err := s.repo.GetPoints(id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PointsAccount{
+		CustomerID: id,
+		Balance:    balance,
+		Entries:    models.NewPage(entries, total, limit, offset),
+	}, nil
+}
+
+func validateCustomer(customer *models.Customer) error {
+	customer.Name = strings.TrimSpace(customer.Name)
+	if customer.Name == "" {
+		return models.NewValidationError("name is required")
+	}
+
+	if customer.Phone != nil {
+		phone := normalizePhone(*customer.Phone)
+		switch {
+		case strings.TrimSpace(*customer.Phone) == "":
+			customer.Phone = nil
+		case len(phone) < 8 || len(phone) > 15:
+			return models.NewValidationError("phone must be 8 to 15 digits")
+		default:
+			customer.Phone = &phone
+		}
+	}
+	if customer.MemberCard != nil {
+		card := strings.TrimSpace(*customer.MemberCard)
+		customer.MemberCard = optionalString(card)
+		if len(card) > 50 {
+			return models.NewValidationError("member_card must be at most 50 characters")
+		}
+	}
+
+	customer.Email = strings.TrimSpace(customer.Email)
+	customer.Note = strings.TrimSpace(customer.Note)
+	return nil
+}
+
+// normalizePhone keeps the digits of a phone number and writes the country
+// code as a leading 0, +62 812-3456 becomes 08123456. It returns "" when
+// value has no digits.
+func normalizePhone(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	phone := b.String()
+	if strings.HasPrefix(phone, "62") {
+		phone = "0" + phone[2:]
+	}
+	return phone
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"kasir-api/models"
+	"net"
+	"time"
+)
+
+// ESC/POS commands
+var (
+	escposInit       = []byte{0x1b, 0x40}                   // ESC @
+	escposBoldOn     = []byte{0x1b, 0x45, 0x01}             // ESC E 1
+	escposBoldOff    = []byte{0x1b, 0x45, 0x00}             // ESC E 0
+	escposLargeOn    = []byte{0x1d, 0x21, 0x01}             // GS ! double height
+	escposLargeOff   = []byte{0x1d, 0x21, 0x00}             // GS ! normal
+	escposFeed       = []byte{0x1b, 0x64, 0x04}             // ESC d 4, feed past the cutter
+	escposCut        = []byte{0x1d, 0x56, 0x42, 0x00}       // GS V B 0, partial cut
+	escposDrawerKick = []byte{0x1b, 0x70, 0x00, 0x19, 0xfa} // ESC p pin 2, 50ms on, 500ms off
+)
+
+const (
+	defaultPrinterPort = "9100"
+	printerTimeout     = 5 * time.Second
+)
+
+// encodeESCPOS turns the receipt into printer commands, ending with a cut
+// and optionally a cash drawer kick. Characters outside ASCII are printed
+// as '?' since code pages differ between printers.
+func encodeESCPOS(lines []receiptLine, openDrawer bool) []byte {
+	out := append([]byte{}, escposInit...)
+	for _, l := range lines {
+		if l.bold {
+			out = append(out, escposBoldOn...)
+		}
+		if l.large {
+			out = append(out, escposLargeOn...)
+		}
+		for _, r := range l.text {
+			if r > 0x7e || (r < 0x20 && r != '\n') {
+				r = '?'
+			}
+			out = append(out, byte(r))
+		}
+		out = append(out, '\n')
+		if l.large {
+			out = append(out, escposLargeOff...)
+		}
+		if l.bold {
+			out = append(out, escposBoldOff...)
+		}
+	}
+
+	out = append(out, escposFeed...)
+	out = append(out, escposCut...)
+	if openDrawer {
+		out = append(out, escposDrawerKick...)
+	}
+	return out
+}
+
+// sendToPrinter writes raw bytes to a network printer (port 9100 unless
+// the address has one) and returns the address used
+func sendToPrinter(address string, data []byte) (string, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, defaultPrinterPort)
+	}
+
+	conn, err := net.DialTimeout("tcp", address, printerTimeout)
+	if err != nil {
+		return address, &models.PrinterError{Printer: address, Err: err}
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(printerTimeout)); err != nil {
+		return address, &models.PrinterError{Printer: address, Err: err}
+	}
+	if _, err := conn.Write(data); err != nil {
+		return address, &models.PrinterError{Printer: address, Err: err}
+	}
+
+	return address, nil
+}
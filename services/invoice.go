@@ -0,0 +1,244 @@
+package services
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+// InvoiceConfig holds the store identity printed on every invoice
+type InvoiceConfig struct {
+	Header []string // lines under the outlet name, e.g. the legal company name
+	Footer []string
+	NPWP   string // tax number of the store
+}
+
+type InvoiceService struct {
+	transactions *repositories.TransactionRepository
+	outlets      *repositories.OutletRepository
+	config       InvoiceConfig
+}
+
+func NewInvoiceService(transactions *repositories.TransactionRepository, outlets *repositories.OutletRepository, config InvoiceConfig) *InvoiceService {
+	return &InvoiceService{transactions: transactions, outlets: outlets, config: config}
+}
+
+// InvoiceNumber is derived from the sale, INV/20240131/000042. Refunds get
+// a credit note number, CN/...
+func InvoiceNumber(t *models.Transaction) string {
+	prefix := "INV"
+	if t.RefundOfID != nil {
+		prefix = "CN"
+	}
+	return fmt.Sprintf("%s/%s/%06d", prefix, t.CreatedAt.Format("20060102"), t.ID)
+}
+
+// Page layout, in points
+const (
+	invoiceMargin = 40.0
+	invoiceRight  = pdfPageWidth - invoiceMargin
+	invoiceBottom = 60.0
+
+	// columns of the item table, No and Item are left aligned at their
+	// position, the others right aligned
+	invoiceColNo       = invoiceMargin
+	invoiceColItem     = invoiceMargin + 25
+	invoiceColQty      = 330.0
+	invoiceColPrice    = 405.0
+	invoiceColDiscount = 475.0
+	invoiceColAmount   = invoiceRight
+)
+
+// PDF renders the A4 invoice of transaction id
+func (s *InvoiceService) PDF(id int) ([]byte, error) {
+	t, err := s.transactions.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	outlet, err := s.outlets.GetByID(t.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	return s.render(t, outlet), nil
+}
+
+func (s *InvoiceService) render(t *models.Transaction, outlet *models.Outlet) []byte {
+	doc := &pdfDocument{}
+	doc.addPage()
+	y := pdfPageHeight - invoiceMargin
+
+	// identitas toko di kiri, judul dan nomor invoice di kanan
+	title := "INVOICE"
+	if t.RefundOfID != nil {
+		title = "CREDIT NOTE"
+	}
+	doc.textRight(invoiceRight, y-18, 20, true, title)
+	doc.textRight(invoiceRight, y-36, 10, false, "No. "+InvoiceNumber(t))
+	doc.textRight(invoiceRight, y-50, 10, false, "Date: "+t.CreatedAt.Format("02/01/2006 15:04"))
+	if t.RefundOfID != nil {
+		doc.textRight(invoiceRight, y-64, 10, false, fmt.Sprintf("Refund of transaction #%d", *t.RefundOfID))
+	}
+	if t.VoidedAt != nil {
+		doc.textRight(invoiceRight, y-78, 12, true, "VOID")
+	}
+
+	identityWidth := 280.0
+	left := y - 16
+	doc.text(invoiceMargin, left, 16, true, outlet.Name)
+	left -= 16
+	identity := append([]string{}, s.config.Header...)
+	if outlet.Address != "" {
+		identity = append(identity, outlet.Address)
+	}
+	if s.config.NPWP != "" {
+		identity = append(identity, "NPWP: "+formatNPWP(s.config.NPWP))
+	}
+	for _, text := range identity {
+		for _, l := range pdfWrap(text, 9, false, identityWidth) {
+			doc.text(invoiceMargin, left, 9, false, l)
+			left -= 12
+		}
+	}
+	y = min(left, y-90) - 10
+	doc.line(invoiceMargin, y, invoiceRight, y, 1)
+	y -= 20
+
+	if t.Buyer != nil {
+		doc.text(invoiceMargin, y, 9, true, "Bill to")
+		y -= 14
+		doc.text(invoiceMargin, y, 10, true, t.Buyer.Name)
+		y -= 13
+		for _, l := range pdfWrap(t.Buyer.Address, 9, false, identityWidth) {
+			doc.text(invoiceMargin, y, 9, false, l)
+			y -= 12
+		}
+		if t.Buyer.NPWP != "" {
+			doc.text(invoiceMargin, y, 9, false, "NPWP: "+formatNPWP(t.Buyer.NPWP))
+			y -= 12
+		}
+		y -= 10
+	}
+
+	tableHeader := func() {
+		doc.text(invoiceColNo, y, 9, true, "No")
+		doc.text(invoiceColItem, y, 9, true, "Item")
+		doc.textRight(invoiceColQty, y, 9, true, "Qty")
+		doc.textRight(invoiceColPrice, y, 9, true, "Price")
+		doc.textRight(invoiceColDiscount, y, 9, true, "Discount")
+		doc.textRight(invoiceColAmount, y, 9, true, "Amount")
+		y -= 6
+		doc.line(invoiceMargin, y, invoiceRight, y, 0.5)
+		y -= 14
+	}
+	// pindah ke halaman baru kalau sisa tempat tidak cukup
+	ensure := func(height float64, header bool) {
+		if y-height >= invoiceBottom {
+			return
+		}
+		doc.addPage()
+		y = pdfPageHeight - invoiceMargin - 10
+		if header {
+			tableHeader()
+		}
+	}
+
+	tableHeader()
+	for i, d := range t.Details {
+		name := pdfWrap(d.ProductName, 9, false, invoiceColQty-invoiceColItem-40)
+		ensure(float64(len(name))*12, true)
+
+		price := 0
+		if d.Quantity != 0 {
+			price = d.Subtotal / d.Quantity
+		}
+		doc.text(invoiceColNo, y, 9, false, fmt.Sprint(i+1))
+		doc.textRight(invoiceColQty, y, 9, false, fmt.Sprint(d.Quantity))
+		doc.textRight(invoiceColPrice, y, 9, false, formatRupiah(price))
+		if d.Discount != 0 {
+			doc.textRight(invoiceColDiscount, y, 9, false, formatRupiah(-d.Discount))
+		}
+		doc.textRight(invoiceColAmount, y, 9, false, formatRupiah(d.Subtotal-d.Discount))
+		for _, l := range name {
+			doc.text(invoiceColItem, y, 9, false, l)
+			y -= 12
+		}
+		y -= 2
+	}
+	doc.line(invoiceMargin, y+8, invoiceRight, y+8, 0.5)
+	y -= 6
+
+	totals := [][2]string{{"Subtotal", formatRupiah(t.SubtotalAmount)}}
+	if t.ServiceAmount != 0 {
+		totals = append(totals, [2]string{"Service charge", formatRupiah(t.ServiceAmount)})
+	}
+	for _, tax := range t.Taxes {
+		label := fmt.Sprintf("%s %s (DPP %s)", tax.Name, formatRate(tax.Rate), formatRupiah(tax.TaxableAmount))
+		if tax.Inclusive {
+			label += " incl."
+		}
+		totals = append(totals, [2]string{label, formatRupiah(tax.Amount)})
+	}
+	if t.RoundingAmount != 0 {
+		totals = append(totals, [2]string{"Rounding", formatRupiah(t.RoundingAmount)})
+	}
+
+	ensure(float64(len(totals))*14+60, false)
+	for _, total := range totals {
+		doc.textRight(invoiceColDiscount, y, 9, false, total[0])
+		doc.textRight(invoiceColAmount, y, 9, false, total[1])
+		y -= 14
+	}
+	doc.line(invoiceColPrice-60, y+9, invoiceRight, y+9, 0.5)
+	y -= 4
+	doc.textRight(invoiceColDiscount, y, 11, true, "TOTAL")
+	doc.textRight(invoiceColAmount, y, 11, true, "Rp "+formatRupiah(t.TotalAmount))
+	y -= 24
+
+	words := "Terbilang: " + capitalize(terbilang(t.TotalAmount)) + " rupiah"
+	spelled := pdfWrap(words, 9, true, invoiceRight-invoiceMargin)
+	ensure(float64(len(spelled))*12, false)
+	for _, l := range spelled {
+		doc.text(invoiceMargin, y, 9, true, l)
+		y -= 12
+	}
+	y -= 10
+
+	if len(t.Payments) > 0 {
+		ensure(float64(len(t.Payments))*12+14, false)
+		doc.text(invoiceMargin, y, 9, true, "Payment")
+		y -= 13
+		for _, p := range t.Payments {
+			doc.text(invoiceMargin, y, 9, false, strings.ToUpper(p.Method))
+			doc.textRight(invoiceMargin+200, y, 9, false, formatRupiah(p.Amount))
+			y -= 12
+		}
+		y -= 10
+	}
+
+	for _, f := range s.config.Footer {
+		for _, l := range pdfWrap(f, 8, false, invoiceRight-invoiceMargin) {
+			ensure(12, false)
+			doc.text(invoiceMargin, y, 8, false, l)
+			y -= 11
+		}
+	}
+
+	return doc.bytes()
+}
+
+// formatNPWP writes 15 digits as 01.234.567.8-901.000, the 16 digit NPWP
+// is written as is
+func formatNPWP(npwp string) string {
+	if len(npwp) != 15 {
+		return npwp
+	}
+	return npwp[0:2] + "." + npwp[2:5] + "." + npwp[5:8] + "." + npwp[8:9] + "-" + npwp[9:12] + "." + npwp[12:15]
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
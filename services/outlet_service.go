@@ -0,0 +1,63 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+type OutletService struct {
+	repo *repositories.OutletRepository
+}
+
+func NewOutletService(repo *repositories.OutletRepository) *OutletService {
+	return &OutletService{repo: repo}
+}
+
+func (s *OutletService) GetAll() ([]models.Outlet, error) {
+	return s.repo.GetAll()
+}
+
+func (s *OutletService) GetByID(id int) (*models.Outlet, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *OutletService) Create(outlet *models.Outlet) error {
+	if err := validateOutlet(outlet); err != nil {
+		return err
+	}
+	return s.repo.Create(outlet)
+}
+
+func (s *OutletService) Update(outlet *models.Outlet) error {
+	if err := validateOutlet(outlet); err != nil {
+		return err
+	}
+	return s.repo.Update(outlet)
+}
+
+func (s *OutletService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+func validateOutlet(outlet *models.Outlet) error {
+	outlet.Name = strings.TrimSpace(outlet.Name)
+	if outlet.Name == "" {
+		return models.NewValidationError("name is required")
+	}
+	outlet.Address = strings.TrimSpace(outlet.Address)
+	if outlet.ServiceChargeRate < 0 || outlet.ServiceChargeRate > 100 {
+		return models.NewValidationError("service_charge_rate must be between 0 and 100")
+	}
+	if outlet.RoundingUnit < 0 {
+		return models.NewValidationError("rounding_unit must not be negative")
+	}
+	switch outlet.RoundingMode {
+	case "":
+		outlet.RoundingMode = models.RoundingNearest
+	case models.RoundingNearest, models.RoundingUp, models.RoundingDown:
+	default:
+		return models.NewValidationError("rounding_mode must be nearest, up or down")
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"kasir-api/models"
+	"strconv"
+	"strings"
+)
+
+// productCSVColumns is the column layout shared by import and export
+var productCSVColumns = []string{"sku", "name", "price", "stock", "category_id", "barcodes"}
+
+// barcodeSeparator separates multiple barcodes inside one CSV cell
+const barcodeSeparator = "|"
+
+// Import parses a product CSV, validates every row and saves them in one
+// database transaction. Nothing is saved when any row fails or dryRun is set.
+// Stock in the file is booked at outletID.
+func (s *ProductService) Import(r io.Reader, mode string, dryRun bool, outletID int, operator string) (*models.ProductImportResult, error) {
+	if mode == "" {
+		mode = models.ImportModeCreate
+	}
+	if mode != models.ImportModeCreate && mode != models.ImportModeUpsert {
+		return nil, models.NewValidationError("mode must be create or upsert")
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, models.NewValidationError("csv file is empty")
+	}
+	if err != nil {
+		return nil, models.NewValidationError("invalid csv: %v", err)
+	}
+
+	columns, err := parseProductCSVHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ProductImportResult{
+		DryRun: dryRun,
+		Mode:   mode,
+		Errors: make([]models.ProductImportError, 0),
+	}
+
+	rows := make([]models.ProductImportRow, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		line, _ := reader.FieldPos(0)
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			result.Errors = append(result.Errors, models.ProductImportError{Line: parseErr.Line, Message: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if isBlankRecord(record) {
+			continue
+		}
+		result.TotalRows++
+
+		row, err := parseProductCSVRecord(line, columns, record)
+		if err != nil {
+			result.Errors = append(result.Errors, models.ProductImportError{Line: line, Message: err.Error()})
+			continue
+		}
+		rows = append(rows, *row)
+	}
+
+	if result.TotalRows == 0 && len(result.Errors) == 0 {
+		return nil, models.NewValidationError("csv file has no data rows")
+	}
+
+	present := make(map[string]bool, len(columns))
+	for _, name := range columns {
+		if name != "" {
+			present[name] = true
+		}
+	}
+	for i := range rows {
+		rows[i].Columns = present
+	}
+
+	// baris yang valid tetap dicek ke database supaya semua error terlapor sekaligus
+	commit := !dryRun && len(result.Errors) == 0
+	created, updated, rowErrors, err := s.repo.Import(rows, mode, commit, outletID, optionalString(operator))
+	if err != nil {
+		return nil, err
+	}
+
+	result.Errors = append(result.Errors, rowErrors...)
+	if len(result.Errors) == 0 {
+		result.Created = created
+		result.Updated = updated
+	}
+
+	return result, nil
+}
+
+// Export writes the whole catalog as CSV in the import column layout
+func (s *ProductService) Export(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(productCSVColumns); err != nil {
+		return err
+	}
+
+	err := s.repo.Export(func(p models.Product) error {
+		codes := make([]string, 0, len(p.Barcodes))
+		for _, b := range p.Barcodes {
+			codes = append(codes, b.Code)
+		}
+
+		record := []string{
+			"",
+			p.Name,
+			strconv.Itoa(p.Price),
+			strconv.Itoa(p.Stock),
+			"",
+			strings.Join(codes, barcodeSeparator),
+		}
+		if p.SKU != nil {
+			record[0] = *p.SKU
+		}
+		if p.CategoryID != nil {
+			record[4] = strconv.Itoa(*p.CategoryID)
+		}
+
+		return writer.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// parseProductCSVHeader maps each column index to a known column name,
+// unknown columns are ignored
+func parseProductCSVHeader(header []string) ([]string, error) {
+	known := make(map[string]bool, len(productCSVColumns))
+	for _, name := range productCSVColumns {
+		known[name] = true
+	}
+
+	columns := make([]string, len(header))
+	seen := make(map[string]bool)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !known[name] {
+			continue
+		}
+		if seen[name] {
+			return nil, models.NewValidationError("duplicate column %s", name)
+		}
+		seen[name] = true
+		columns[i] = name
+	}
+
+	if !seen["name"] || !seen["price"] {
+		return nil, models.NewValidationError("csv header must contain name and price columns")
+	}
+
+	return columns, nil
+}
+
+func parseProductCSVRecord(line int, columns []string, record []string) (*models.ProductImportRow, error) {
+	row := &models.ProductImportRow{
+		Line:    line,
+		Product: models.Product{Barcodes: make([]models.Barcode, 0)},
+	}
+	p := &row.Product
+
+	for i, value := range record {
+		if i >= len(columns) || columns[i] == "" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch columns[i] {
+		case "sku":
+			if value != "" {
+				p.SKU = &value
+			}
+		case "name":
+			p.Name = value
+		case "price":
+			p.Price, err = strconv.Atoi(value)
+		case "stock":
+			if value != "" {
+				p.Stock, err = strconv.Atoi(value)
+			}
+		case "category_id":
+			if value != "" {
+				var id int
+				id, err = strconv.Atoi(value)
+				p.CategoryID = &id
+			}
+		case "barcodes":
+			for _, code := range strings.Split(value, barcodeSeparator) {
+				if code = strings.TrimSpace(code); code != "" {
+					p.Barcodes = append(p.Barcodes, models.Barcode{Code: code})
+				}
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a whole number", columns[i])
+		}
+	}
+
+	if p.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if p.Price < 0 {
+		return nil, errors.New("price must not be negative")
+	}
+	if err := normalizeProduct(p); err != nil {
+		return nil, err
+	}
+
+	return row, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"kasir-api/models"
 	"kasir-api/repositories"
+	"strings"
 )
 
 type ProductService struct {
@@ -13,22 +14,167 @@ func NewProductService(repo *repositories.ProductRepository) *ProductService {
 	return &ProductService{repo: repo}
 }
 
-func (s *ProductService) GetAll() ([]models.Product, error) {
-	return s.repo.GetAll()
+const (
+	defaultProductPageSize = 50
+	maxProductPageSize     = 200
+)
+
+// GetAll returns one page of the products matching the filter
+func (s *ProductService) GetAll(filter models.ProductFilter) (*models.Page[models.Product], error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultProductPageSize
+	}
+	if filter.Limit > maxProductPageSize {
+		filter.Limit = maxProductPageSize
+	}
+	if filter.Offset < 0 {
+		return nil, models.NewValidationError("offset must not be negative")
+	}
+	if err := validateProductFilter(filter); err != nil {
+		return nil, err
+	}
+
+	products, total, err := s.repo.GetAll(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewPage(products, total, filter.Limit, filter.Offset), nil
 }
 
-func (s *ProductService) Create(data *models.Product) error {
-	return s.repo.Create(data)
+// List returns every product matching the filter, without paging
+func (s *ProductService) List(filter models.ProductFilter) ([]models.Product, error) {
+	filter.Limit, filter.Offset = 0, 0
+	if err := validateProductFilter(filter); err != nil {
+		return nil, err
+	}
+
+	products, _, err := s.repo.GetAll(filter)
+	return products, err
+}
+
+func validateProductFilter(filter models.ProductFilter) error {
+	switch filter.Sort {
+	case "", "id", "name", "price", "stock":
+	default:
+		return models.NewValidationError("sort must be one of id, name, price, stock")
+	}
+	switch filter.Order {
+	case "", "asc", "desc":
+	default:
+		return models.NewValidationError("order must be asc or desc")
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return models.NewValidationError("min_price must not be greater than max_price")
+	}
+	return nil
+}
+
+// Create saves a new product, its opening stock is booked at outletID
+func (s *ProductService) Create(data *models.Product, outletID int, operator string) error {
+	if err := normalizeProduct(data); err != nil {
+		return err
+	}
+	return s.repo.Create(data, outletID, optionalString(operator))
 }
 
 func (s *ProductService) GetByID(id int) (*models.Product, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *ProductService) Update(product *models.Product) error {
-	return s.repo.Update(product)
+func (s *ProductService) GetByBarcode(code string) (*models.Product, error) {
+	return s.repo.GetByBarcode(strings.TrimSpace(code))
+}
+
+// Update saves a product, a change of the total stock is booked at outletID
+func (s *ProductService) Update(product *models.Product, outletID int, operator string) error {
+	if err := normalizeProduct(product); err != nil {
+		return err
+	}
+	return s.repo.Update(product, outletID, optionalString(operator))
+}
+
+// maxVariants limits how many variants one parent product can have
+const maxVariants = 200
+
+// GenerateVariants sets the option axes of a parent product and creates the
+// missing variants, then returns the parent with all its variants
+func (s *ProductService) GenerateVariants(parentID int, req models.VariantGenerateRequest, outletID int, operator string) (*models.Product, error) {
+	if len(req.Options) == 0 {
+		return nil, models.NewValidationError("options must not be empty")
+	}
+	if req.Price != nil && *req.Price < 0 {
+		return nil, models.NewValidationError("price must not be negative")
+	}
+
+	combinations := 1
+	names := make(map[string]bool, len(req.Options))
+	for i := range req.Options {
+		option := &req.Options[i]
+		option.Name = strings.TrimSpace(option.Name)
+		if option.Name == "" {
+			return nil, models.NewValidationError("options[%d]: name is required", i)
+		}
+		if names[strings.ToLower(option.Name)] {
+			return nil, models.NewValidationError("options[%d]: duplicate option %s", i, option.Name)
+		}
+		names[strings.ToLower(option.Name)] = true
+
+		if len(option.Values) == 0 {
+			return nil, models.NewValidationError("options[%d]: values must not be empty", i)
+		}
+		values := make(map[string]bool, len(option.Values))
+		for j, value := range option.Values {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return nil, models.NewValidationError("options[%d]: values[%d] must not be empty", i, j)
+			}
+			if values[strings.ToLower(value)] {
+				return nil, models.NewValidationError("options[%d]: duplicate value %s", i, value)
+			}
+			values[strings.ToLower(value)] = true
+			option.Values[j] = value
+		}
+
+		combinations *= len(option.Values)
+		if combinations > maxVariants {
+			return nil, models.NewValidationError("options produce more than %d variants", maxVariants)
+		}
+	}
+
+	if err := s.repo.GenerateVariants(parentID, req.Options, req.Price, outletID, optionalString(operator)); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(parentID)
 }
 
 func (s *ProductService) Delete(id int) error {
 	return s.repo.Delete(id)
 }
+
+// optionalString returns nil for an empty string
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// normalizeProduct trims the SKU and validates barcodes before saving
+func normalizeProduct(product *models.Product) error {
+	if product.SKU != nil {
+		sku := strings.TrimSpace(*product.SKU)
+		if sku == "" {
+			product.SKU = nil
+		} else {
+			product.SKU = &sku
+		}
+	}
+	if product.Stock < 0 {
+		return models.NewValidationError("stock must not be negative")
+	}
+	if product.Barcodes == nil {
+		product.Barcodes = make([]models.Barcode, 0)
+	}
+	return normalizeBarcodes(product.Barcodes)
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+	"time"
+)
+
+type PromotionService struct {
+	repo *repositories.PromotionRepository
+}
+
+func NewPromotionService(repo *repositories.PromotionRepository) *PromotionService {
+	return &PromotionService{repo: repo}
+}
+
+func (s *PromotionService) GetAll(activeOnly bool) ([]models.Promotion, error) {
+	return s.repo.GetAll(activeOnly)
+}
+
+func (s *PromotionService) GetByID(id int) (*models.Promotion, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *PromotionService) Create(promotion *models.Promotion) error {
+	if err := validatePromotion(promotion); err != nil {
+		return err
+	}
+	return s.repo.Create(promotion)
+}
+
+func (s *PromotionService) Update(promotion *models.Promotion) error {
+	if err := validatePromotion(promotion); err != nil {
+		return err
+	}
+	return s.repo.Update(promotion)
+}
+
+func (s *PromotionService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+// validatePromotion checks the rule and clears fields its type and scope do not use
+func validatePromotion(p *models.Promotion) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return models.NewValidationError("name is required")
+	}
+
+	switch p.Scope {
+	case models.PromoScopeItem:
+		if p.ProductID == nil {
+			return models.NewValidationError("product_id is required for item promotions")
+		}
+		p.CategoryID = nil
+	case models.PromoScopeCategory:
+		if p.CategoryID == nil {
+			return models.NewValidationError("category_id is required for category promotions")
+		}
+		p.ProductID = nil
+	case models.PromoScopeCart:
+		p.ProductID, p.CategoryID = nil, nil
+	default:
+		return models.NewValidationError("scope must be item, category or cart")
+	}
+
+	switch p.Type {
+	case models.PromoPercentage:
+		if p.Value <= 0 || p.Value > 100 {
+			return models.NewValidationError("value must be a percentage between 1 and 100")
+		}
+	case models.PromoFixed:
+		if p.Value <= 0 {
+			return models.NewValidationError("value must be greater than zero")
+		}
+	case models.PromoBuyXGetY:
+		if p.BuyQty <= 0 || p.GetQty <= 0 {
+			return models.NewValidationError("buy_qty and get_qty must be greater than zero")
+		}
+	case models.PromoBundle:
+		if p.BundleQty < 2 {
+			return models.NewValidationError("bundle_qty must be at least 2")
+		}
+		if p.BundlePrice < 0 {
+			return models.NewValidationError("bundle_price must not be negative")
+		}
+	default:
+		return models.NewValidationError("type must be percentage, fixed, buy_x_get_y or bundle")
+	}
+	if p.Scope == models.PromoScopeCart && p.Type != models.PromoPercentage && p.Type != models.PromoFixed {
+		return models.NewValidationError("cart promotions must be percentage or fixed")
+	}
+	if p.Type != models.PromoBuyXGetY {
+		p.BuyQty, p.GetQty = 0, 0
+	}
+	if p.Type != models.PromoBundle {
+		p.BundleQty, p.BundlePrice = 0, 0
+	}
+	if p.Type == models.PromoBuyXGetY || p.Type == models.PromoBundle {
+		p.Value = 0
+	}
+
+	if p.MinSpend < 0 {
+		return models.NewValidationError("min_spend must not be negative")
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.StartsAt.Before(*p.EndsAt) {
+		return models.NewValidationError("starts_at must be before ends_at")
+	}
+
+	p.StartTime = optionalTrimmed(p.StartTime)
+	p.EndTime = optionalTrimmed(p.EndTime)
+	if (p.StartTime == nil) != (p.EndTime == nil) {
+		return models.NewValidationError("start_time and end_time must be set together")
+	}
+	for _, value := range []*string{p.StartTime, p.EndTime} {
+		if value == nil {
+			continue
+		}
+		if _, err := time.Parse("15:04", *value); err != nil {
+			return models.NewValidationError("Invalid time %s. Use HH:MM", *value)
+		}
+	}
+
+	p.CouponCode = optionalTrimmed(p.CouponCode)
+	if p.CouponCode != nil {
+		code := strings.ToUpper(*p.CouponCode)
+		p.CouponCode = &code
+	}
+	if p.UsageLimit != nil {
+		if p.CouponCode == nil {
+			return models.NewValidationError("usage_limit requires a coupon_code")
+		}
+		if *p.UsageLimit <= 0 {
+			return models.NewValidationError("usage_limit must be greater than zero")
+		}
+	}
+
+	return nil
+}
+
+// optionalTrimmed trims the value and returns nil when it is empty
+func optionalTrimmed(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	return optionalString(strings.TrimSpace(*value))
+}
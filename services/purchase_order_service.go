@@ -0,0 +1,95 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+	"time"
+)
+
+type PurchaseOrderService struct {
+	repo *repositories.PurchaseOrderRepository
+}
+
+func NewPurchaseOrderService(repo *repositories.PurchaseOrderRepository) *PurchaseOrderService {
+	return &PurchaseOrderService{repo: repo}
+}
+
+func (s *PurchaseOrderService) Create(po *models.PurchaseOrder, operator string) error {
+	if po.SupplierID <= 0 {
+		return models.NewValidationError("supplier_id is required")
+	}
+	if po.ExpectedDate != nil {
+		if _, err := time.Parse("2006-01-02", *po.ExpectedDate); err != nil {
+			return models.NewValidationError("Invalid expected_date format. Use YYYY-MM-DD")
+		}
+	}
+	if len(po.Items) == 0 {
+		return models.NewValidationError("items must not be empty")
+	}
+	for i, item := range po.Items {
+		if item.ProductID <= 0 {
+			return models.NewValidationError("items[%d]: product_id is required", i)
+		}
+		if item.Quantity <= 0 {
+			return models.NewValidationError("items[%d]: quantity must be greater than zero", i)
+		}
+		if item.UnitCost < 0 {
+			return models.NewValidationError("items[%d]: unit_cost must not be negative", i)
+		}
+	}
+
+	po.Note = strings.TrimSpace(po.Note)
+	po.CreatedBy = optionalString(operator)
+	if err := s.repo.Create(po); err != nil {
+		return err
+	}
+
+	created, err := s.repo.GetByID(po.ID)
+	if err != nil {
+		return err
+	}
+	*po = *created
+	return nil
+}
+
+func (s *PurchaseOrderService) GetAll(filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error) {
+	switch filter.Status {
+	case "", models.POStatusOrdered, models.POStatusPartial, models.POStatusReceived, models.POStatusCancelled:
+	default:
+		return nil, models.NewValidationError("status must be ordered, partial, received or cancelled")
+	}
+	return s.repo.GetAll(filter)
+}
+
+func (s *PurchaseOrderService) GetByID(id int) (*models.PurchaseOrder, error) {
+	return s.repo.GetByID(id)
+}
+
+// Receive posts a (partial) goods receipt and increases stock
+func (s *PurchaseOrderService) Receive(id int, req models.GoodsReceiptRequest, operator string) (*models.GoodsReceipt, error) {
+	if len(req.Items) == 0 {
+		return nil, models.NewValidationError("items must not be empty")
+	}
+	for i, item := range req.Items {
+		if item.ProductID <= 0 {
+			return nil, models.NewValidationError("items[%d]: product_id is required", i)
+		}
+		if item.Quantity <= 0 {
+			return nil, models.NewValidationError("items[%d]: quantity must be greater than zero", i)
+		}
+		if item.UnitCost != nil && *item.UnitCost < 0 {
+			return nil, models.NewValidationError("items[%d]: unit_cost must not be negative", i)
+		}
+	}
+
+	req.Note = strings.TrimSpace(req.Note)
+	return s.repo.Receive(id, req, optionalString(operator))
+}
+
+func (s *PurchaseOrderService) Cancel(id int) (*models.PurchaseOrder, error) {
+	if err := s.repo.Cancel(id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
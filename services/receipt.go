@@ -0,0 +1,342 @@
+package services
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReceiptConfig holds the store identity printed on every receipt and the
+// printers receipts may be sent to. Only configured printers are used, a
+// request picks one by name.
+type ReceiptConfig struct {
+	Header   []string // lines above the outlet name
+	Footer   []string
+	Paper    int               // 58 or 80, defaults to 58
+	Printer  string            // host:port of the default network printer
+	Printers map[string]string // named network printers, name to host:port
+}
+
+type ReceiptService struct {
+	transactions *repositories.TransactionRepository
+	outlets      *repositories.OutletRepository
+	config       ReceiptConfig
+}
+
+func NewReceiptService(transactions *repositories.TransactionRepository, outlets *repositories.OutletRepository, config ReceiptConfig) *ReceiptService {
+	if config.Paper == 0 {
+		config.Paper = models.Paper58mm
+	}
+	return &ReceiptService{transactions: transactions, outlets: outlets, config: config}
+}
+
+// Render returns the receipt of a transaction as plain text or ESC/POS
+// bytes. Rendering does not count as a print, a receipt that was printed
+// before is marked COPY.
+func (s *ReceiptService) Render(id int, format string, paper int) ([]byte, error) {
+	width, err := s.paperWidth(paper)
+	if err != nil {
+		return nil, err
+	}
+	if format != models.ReceiptFormatText && format != models.ReceiptFormatESCPOS {
+		return nil, models.NewValidationError("format must be text or escpos")
+	}
+
+	t, err := s.transactions.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.layout(t, width, t.PrintCount > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == models.ReceiptFormatESCPOS {
+		return encodeESCPOS(lines, false), nil
+	}
+	return []byte(encodeText(lines)), nil
+}
+
+// Print sends the receipt to a network printer and counts the print.
+// It returns the printer address and whether the receipt was a copy.
+func (s *ReceiptService) Print(id int, req models.PrintRequest) (string, bool, error) {
+	width, err := s.paperWidth(req.Paper)
+	if err != nil {
+		return "", false, err
+	}
+
+	printer, err := s.printerAddress(req.Printer)
+	if err != nil {
+		return "", false, err
+	}
+
+	// print dihitung sebelum dikirim supaya dua print bersamaan tidak
+	// sama-sama dianggap cetakan pertama
+	printed, err := s.transactions.MarkPrinted(id)
+	if err != nil {
+		return "", false, err
+	}
+	isCopy := printed > 0
+
+	transaction, err := s.transactions.GetByID(id)
+	if err != nil {
+		return "", false, err
+	}
+	lines, err := s.layout(transaction, width, isCopy)
+	if err != nil {
+		return "", false, err
+	}
+
+	// laci kas dibuka untuk pembayaran tunai, tidak untuk cetak ulang
+	openDrawer := !isCopy && hasCashPayment(transaction)
+	if req.OpenDrawer != nil {
+		openDrawer = *req.OpenDrawer
+	}
+
+	printer, err = sendToPrinter(printer, encodeESCPOS(lines, openDrawer))
+	if err != nil {
+		// struk tidak tercetak, jangan sampai cetakan berikutnya jadi COPY
+		if unmarkErr := s.transactions.UnmarkPrinted(id); unmarkErr != nil {
+			return "", false, unmarkErr
+		}
+		return "", false, err
+	}
+
+	return printer, isCopy, nil
+}
+
+// printerAddress returns the address of the configured printer with the
+// given name, the default printer when name is empty. Addresses that are
+// not configured are refused so the API cannot be used to reach other hosts.
+func (s *ReceiptService) printerAddress(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		if s.config.Printer == "" {
+			return "", models.NewValidationError("printer is required, no default printer is configured")
+		}
+		return s.config.Printer, nil
+	}
+
+	address, ok := s.config.Printers[name]
+	if !ok {
+		return "", models.NewValidationError("unknown printer %s", name)
+	}
+	return address, nil
+}
+
+func (s *ReceiptService) paperWidth(paper int) (int, error) {
+	if paper == 0 {
+		paper = s.config.Paper
+	}
+	switch paper {
+	case models.Paper58mm:
+		return 32, nil
+	case models.Paper80mm:
+		return 48, nil
+	}
+	return 0, models.NewValidationError("paper must be 58 or 80")
+}
+
+// receiptLine is one line of the receipt, text is already laid out for the
+// paper width
+type receiptLine struct {
+	text  string
+	bold  bool
+	large bool // double height
+}
+
+func (s *ReceiptService) layout(t *models.Transaction, width int, isCopy bool) ([]receiptLine, error) {
+	outlet, err := s.outlets.GetByID(t.OutletID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]receiptLine, 0)
+	add := func(text string) { lines = append(lines, receiptLine{text: text}) }
+	center := func(text string, bold bool) {
+		for _, l := range wrapText(text, width) {
+			lines = append(lines, receiptLine{text: centerText(l, width), bold: bold})
+		}
+	}
+	separator := func() { add(strings.Repeat("-", width)) }
+
+	for _, h := range s.config.Header {
+		center(h, false)
+	}
+	center(outlet.Name, true)
+	if outlet.Address != "" {
+		center(outlet.Address, false)
+	}
+
+	if isCopy {
+		center("*** COPY ***", true)
+	}
+	if t.VoidedAt != nil {
+		center("*** VOID ***", true)
+	}
+	if t.RefundOfID != nil {
+		center(fmt.Sprintf("REFUND OF #%d", *t.RefundOfID), true)
+	}
+
+	separator()
+	add(columns(fmt.Sprintf("No: %d", t.ID), t.CreatedAt.Format("02/01/2006 15:04"), width))
+	if t.CreatedBy != nil {
+		add(truncateText("Cashier: "+*t.CreatedBy, width))
+	}
+	separator()
+
+	for _, d := range t.Details {
+		for _, l := range wrapText(d.ProductName, width) {
+			add(l)
+		}
+		price := 0
+		if d.Quantity != 0 {
+			price = d.Subtotal / d.Quantity
+		}
+		add(columns(fmt.Sprintf("  %d x %s", d.Quantity, formatRupiah(price)), formatRupiah(d.Subtotal), width))
+		if d.Discount != 0 {
+			add(columns("  Discount", formatRupiah(-d.Discount), width))
+		}
+	}
+	separator()
+
+	add(columns("Subtotal", formatRupiah(t.GrossAmount), width))
+	if t.DiscountAmount != 0 {
+		add(columns("Discount", formatRupiah(-t.DiscountAmount), width))
+	}
+	if t.PointsDiscount != 0 {
+		add(columns(fmt.Sprintf("  %d points redeemed", t.PointsRedeemed), formatRupiah(-t.PointsDiscount), width))
+	}
+	if t.ServiceAmount != 0 {
+		add(columns("Service charge", formatRupiah(t.ServiceAmount), width))
+	}
+	for _, tax := range t.Taxes {
+		label := tax.Name + " " + formatRate(tax.Rate)
+		if tax.Inclusive {
+			label += " (incl.)"
+		}
+		add(columns(label, formatRupiah(tax.Amount), width))
+	}
+	if t.RoundingAmount != 0 {
+		add(columns("Rounding", formatRupiah(t.RoundingAmount), width))
+	}
+	lines = append(lines, receiptLine{text: columns("TOTAL", formatRupiah(t.TotalAmount), width), bold: true, large: true})
+
+	for _, p := range t.Payments {
+		add(columns(strings.ToUpper(p.Method), formatRupiah(p.Amount), width))
+	}
+	if t.Change != 0 {
+		add(columns("Change", formatRupiah(t.Change), width))
+	}
+	if t.PointsEarned != 0 {
+		add(columns("Points earned", fmt.Sprint(t.PointsEarned), width))
+	}
+
+	if len(s.config.Footer) > 0 {
+		separator()
+		for _, f := range s.config.Footer {
+			center(f, false)
+		}
+	}
+
+	return lines, nil
+}
+
+func hasCashPayment(t *models.Transaction) bool {
+	for _, p := range t.Payments {
+		if p.Method == models.PaymentCash && p.Amount > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeText(lines []receiptLine) string {
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(strings.TrimRight(l.text, " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// formatRupiah formats an amount with dots as thousands separators
+func formatRupiah(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.Itoa(amount)
+	var b strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(c)
+	}
+	return sign + b.String()
+}
+
+// formatRate formats a tax rate such as 11 or 0.5 as a percentage
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', -1, 64) + "%"
+}
+
+// columns puts left and right on one line of the given width, the left
+// part is cut when both do not fit
+func columns(left, right string, width int) string {
+	space := width - utf8.RuneCountInString(right) - 1
+	left = truncateText(left, max(space, 0))
+	pad := max(width-utf8.RuneCountInString(left)-utf8.RuneCountInString(right), 0)
+	return left + strings.Repeat(" ", pad) + right
+}
+
+func centerText(text string, width int) string {
+	pad := (width - utf8.RuneCountInString(text)) / 2
+	if pad <= 0 {
+		return text
+	}
+	return strings.Repeat(" ", pad) + text
+}
+
+func truncateText(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	return string([]rune(text)[:width])
+}
+
+// wrapText breaks text on spaces into lines of at most width characters,
+// words longer than a line are cut
+func wrapText(text string, width int) []string {
+	lines := make([]string, 0)
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for utf8.RuneCountInString(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			lines = append(lines, string([]rune(word)[:width]))
+			word = string([]rune(word)[width:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"kasir-api/models"
+	"net"
+	"testing"
+)
+
+func TestPrinterAddress(t *testing.T) {
+	s := &ReceiptService{config: ReceiptConfig{
+		Printer:  "10.0.0.5:9100",
+		Printers: map[string]string{"kitchen": "10.0.0.6:9100", "bar": "10.0.0.7"},
+	}}
+
+	tests := []struct {
+		name    string
+		printer string
+		want    string
+		wantErr bool
+	}{
+		{"default", "", "10.0.0.5:9100", false},
+		{"named", "kitchen", "10.0.0.6:9100", false},
+		{"name is case insensitive", " Bar ", "10.0.0.7", false},
+		{"unknown name", "office", "", true},
+		{"address is not a name", "10.0.0.6:9100", "", true},
+		{"internal host refused", "127.0.0.1:6379", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.printerAddress(tt.printer)
+			var validationErr *models.ValidationError
+			if tt.wantErr {
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("printerAddress(%q) error = %v, want a validation error", tt.printer, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("printerAddress(%q) error = %v", tt.printer, err)
+			}
+			if got != tt.want {
+				t.Errorf("printerAddress(%q) = %q, want %q", tt.printer, got, tt.want)
+			}
+		})
+	}
+
+	s.config.Printer = ""
+	if _, err := s.printerAddress(""); err == nil {
+		t.Error("printerAddress without a default printer succeeded")
+	}
+}
+
+func TestSendToPrinter(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	data := encodeESCPOS([]receiptLine{{text: "TOTAL", bold: true}}, true)
+	address, err := sendToPrinter(listener.Addr().String(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if address != listener.Addr().String() {
+		t.Errorf("address = %q, want %q", address, listener.Addr().String())
+	}
+	if got := <-received; !bytes.Equal(got, data) {
+		t.Errorf("printer received %q, want %q", got, data)
+	}
+
+	// printer yang mati dilaporkan sebagai PrinterError
+	listener.Close()
+	var printerErr *models.PrinterError
+	if _, err := sendToPrinter(listener.Addr().String(), data); !errors.As(err, &printerErr) {
+		t.Errorf("send to a closed printer error = %v, want a PrinterError", err)
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"slices"
+	"strings"
+)
+
+type RoleService struct {
+	repo *repositories.RoleRepository
+}
+
+func NewRoleService(repo *repositories.RoleRepository) *RoleService {
+	return &RoleService{repo: repo}
+}
+
+func (s *RoleService) GetAll() ([]models.Role, error) {
+	return s.repo.GetAll()
+}
+
+func (s *RoleService) GetByID(id int) (*models.Role, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *RoleService) Create(role *models.Role) error {
+	if err := normalizeRole(role); err != nil {
+		return err
+	}
+	return s.repo.Create(role)
+}
+
+func (s *RoleService) Update(role *models.Role) error {
+	if err := normalizeRole(role); err != nil {
+		return err
+	}
+	return s.repo.Update(role)
+}
+
+func (s *RoleService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+// normalizeRole lowercases the name and checks and sorts the permissions
+func normalizeRole(role *models.Role) error {
+	name, err := normalizeRoleName(role.Name)
+	if err != nil {
+		return err
+	}
+	role.Name = name
+	role.Description = strings.TrimSpace(role.Description)
+
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permission = strings.ToLower(strings.TrimSpace(permission))
+		if !slices.Contains(models.Permissions, permission) {
+			return models.NewValidationError("unknown permission %q, must be one of %s", permission, strings.Join(models.Permissions, ", "))
+		}
+		if !slices.Contains(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+	slices.Sort(permissions)
+	role.Permissions = permissions
+	return nil
+}
+
+func normalizeRoleName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", models.NewValidationError("role name is required")
+	}
+	// nama role disimpan dipisah koma di daftar role user
+	if len(name) > 50 || strings.ContainsAny(name, ", \t\r\n") {
+		return "", models.NewValidationError("role name must be at most 50 characters without spaces or commas")
+	}
+	return name, nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"slices"
+	"strings"
+)
+
+type ShiftService struct {
+	repo *repositories.ShiftRepository
+}
+
+func NewShiftService(repo *repositories.ShiftRepository) *ShiftService {
+	return &ShiftService{repo: repo}
+}
+
+const (
+	defaultShiftPageSize = 50
+	maxShiftPageSize     = 200
+)
+
+// Open starts a shift for the operator at req.OutletID, or at outletID when
+// the request does not name one
+func (s *ShiftService) Open(req models.OpenShiftRequest, outletID int, operator string) (*models.Shift, error) {
+	if operator == "" {
+		return nil, models.NewValidationError("operator is required to open a shift")
+	}
+	if req.OpeningFloat == nil {
+		return nil, models.NewValidationError("opening_float is required")
+	}
+	if *req.OpeningFloat < 0 {
+		return nil, models.NewValidationError("opening_float must not be negative")
+	}
+	if req.OutletID != nil {
+		outletID = *req.OutletID
+	}
+
+	shift := &models.Shift{
+		OutletID:     outletID,
+		Cashier:      operator,
+		OpeningFloat: *req.OpeningFloat,
+		Note:         strings.TrimSpace(req.Note),
+	}
+	if err := s.repo.Open(shift); err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
+func (s *ShiftService) GetAll(filter models.ShiftFilter) (*models.Page[models.Shift], error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultShiftPageSize
+	}
+	if filter.Limit > maxShiftPageSize {
+		filter.Limit = maxShiftPageSize
+	}
+	if filter.Offset < 0 {
+		return nil, models.NewValidationError("offset must not be negative")
+	}
+	switch filter.Status {
+	case "", models.ShiftStatusOpen, models.ShiftStatusClosed:
+	default:
+		return nil, models.NewValidationError("status must be open or closed")
+	}
+
+	shifts, total, err := s.repo.GetAll(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewPage(shifts, total, filter.Limit, filter.Offset), nil
+}
+
+func (s *ShiftService) GetByID(id int) (*models.Shift, error) {
+	return s.repo.GetByID(id)
+}
+
+// GetCurrent returns the open shift of the operator at outletID
+func (s *ShiftService) GetCurrent(outletID int, operator string) (*models.Shift, error) {
+	if operator == "" {
+		return nil, models.NewValidationError("operator is required")
+	}
+	return s.repo.GetOpen(outletID, operator)
+}
+
+// Close records the counted cash per denomination and reports over/short.
+// Without manage the operator can only close their own shift.
+func (s *ShiftService) Close(id int, req models.CloseShiftRequest, operator string, manage bool) (*models.Shift, error) {
+	if len(req.CashCounts) == 0 {
+		return nil, models.NewValidationError("cash_counts must not be empty")
+	}
+
+	seen := make(map[int]bool)
+	counts := make([]models.CashCount, 0, len(req.CashCounts))
+	for i, c := range req.CashCounts {
+		if !slices.Contains(models.Denominations, c.Denomination) {
+			return nil, models.NewValidationError("cash_counts[%d]: %d is not a rupiah denomination", i, c.Denomination)
+		}
+		if c.Quantity < 0 {
+			return nil, models.NewValidationError("cash_counts[%d]: quantity must not be negative", i)
+		}
+		if seen[c.Denomination] {
+			return nil, models.NewValidationError("cash_counts[%d]: denomination %d is listed twice", i, c.Denomination)
+		}
+		seen[c.Denomination] = true
+		counts = append(counts, models.CashCount{Denomination: c.Denomination, Quantity: c.Quantity})
+	}
+	// urutkan dari pecahan terbesar seperti saat dibaca ulang
+	slices.SortFunc(counts, func(a, b models.CashCount) int { return b.Denomination - a.Denomination })
+
+	var owner *string
+	if !manage {
+		owner = &operator
+	}
+	return s.repo.Close(id, counts, strings.TrimSpace(req.Note), optionalString(operator), owner)
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+const (
+	defaultStockHistoryPageSize = 50
+	maxStockHistoryPageSize     = 200
+)
+
+type StockMovementService struct {
+	repo *repositories.StockMovementRepository
+}
+
+func NewStockMovementService(repo *repositories.StockMovementRepository) *StockMovementService {
+	return &StockMovementService{repo: repo}
+}
+
+// GetByProduct - stock history of a product, outletID nil means all outlets
+func (s *StockMovementService) GetByProduct(productID int, outletID *int, limit, offset int) (*models.Page[models.StockMovement], error) {
+	if limit <= 0 {
+		limit = defaultStockHistoryPageSize
+	}
+	if limit > maxStockHistoryPageSize {
+		limit = maxStockHistoryPageSize
+	}
+
+	movements, total, err := s.repo.GetByProduct(productID, outletID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewPage(movements, total, limit, offset), nil
+}
+
+// Adjust records a manual stock change (adjustment, restock or correction)
+// at req.OutletID, or at outletID when the request does not name one
+func (s *StockMovementService) Adjust(productID int, req models.StockAdjustmentRequest, outletID int, operator string) (*models.StockMovement, error) {
+	if req.Delta == 0 {
+		return nil, models.NewValidationError("delta must not be zero")
+	}
+
+	reason := strings.ToLower(strings.TrimSpace(req.Reason))
+	if reason == "" {
+		reason = models.StockReasonAdjustment
+	}
+	switch reason {
+	case models.StockReasonAdjustment, models.StockReasonRestock, models.StockReasonCorrection:
+	default:
+		return nil, models.NewValidationError("reason must be adjustment, restock or correction")
+	}
+	if reason == models.StockReasonRestock && req.Delta < 0 {
+		return nil, models.NewValidationError("restock delta must be positive")
+	}
+
+	if req.OutletID != nil {
+		outletID = *req.OutletID
+	}
+
+	movement := &models.StockMovement{
+		ProductID: productID,
+		OutletID:  outletID,
+		Delta:     req.Delta,
+		Reason:    reason,
+		CreatedBy: optionalString(operator),
+		Note:      strings.TrimSpace(req.Note),
+	}
+	if err := s.repo.Adjust(movement); err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+type StockOpnameService struct {
+	repo *repositories.StockOpnameRepository
+}
+
+func NewStockOpnameService(repo *repositories.StockOpnameRepository) *StockOpnameService {
+	return &StockOpnameService{repo: repo}
+}
+
+// Open starts a count at req.OutletID, or at outletID when the request does
+// not name one
+func (s *StockOpnameService) Open(req models.OpenOpnameRequest, outletID int, operator string) (*models.StockOpname, error) {
+	if req.OutletID != nil {
+		outletID = *req.OutletID
+	}
+
+	opname := &models.StockOpname{
+		OutletID:   outletID,
+		CategoryID: req.CategoryID,
+		Note:       strings.TrimSpace(req.Note),
+		CreatedBy:  optionalString(operator),
+	}
+	if err := s.repo.Open(opname); err != nil {
+		return nil, err
+	}
+	return opname, nil
+}
+
+func (s *StockOpnameService) GetAll(status string) ([]models.StockOpname, error) {
+	switch status {
+	case "", models.OpnameStatusOpen, models.OpnameStatusPosted, models.OpnameStatusCancelled:
+	default:
+		return nil, models.NewValidationError("status must be open, posted or cancelled")
+	}
+	return s.repo.GetAll(status)
+}
+
+func (s *StockOpnameService) GetByID(id int) (*models.StockOpname, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *StockOpnameService) SubmitCounts(id int, req models.OpnameCountRequest, operator string) (*models.StockOpname, error) {
+	mode := strings.ToLower(req.Mode)
+	if mode == "" {
+		mode = models.CountModeSet
+	}
+	if mode != models.CountModeSet && mode != models.CountModeAdd {
+		return nil, models.NewValidationError("mode must be set or add")
+	}
+	if len(req.Items) == 0 {
+		return nil, models.NewValidationError("items must not be empty")
+	}
+	for i, item := range req.Items {
+		if item.ProductID <= 0 && item.Barcode == "" {
+			return nil, models.NewValidationError("items[%d]: product_id or barcode is required", i)
+		}
+		if item.Quantity < 0 {
+			return nil, models.NewValidationError("items[%d]: quantity must not be negative", i)
+		}
+	}
+
+	if err := s.repo.SubmitCounts(id, mode, req.Items, optionalString(operator)); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+func (s *StockOpnameService) GetVariance(id int) (*models.OpnameVarianceReport, error) {
+	return s.repo.GetVariance(id)
+}
+
+func (s *StockOpnameService) Post(id int, operator string) (*models.StockOpname, error) {
+	if err := s.repo.Post(id, optionalString(operator)); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+func (s *StockOpnameService) Cancel(id int) (*models.StockOpname, error) {
+	if err := s.repo.Cancel(id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+type StockTransferService struct {
+	repo *repositories.StockTransferRepository
+}
+
+func NewStockTransferService(repo *repositories.StockTransferRepository) *StockTransferService {
+	return &StockTransferService{repo: repo}
+}
+
+func (s *StockTransferService) Create(transfer *models.StockTransfer, operator string) error {
+	if transfer.FromOutletID <= 0 || transfer.ToOutletID <= 0 {
+		return models.NewValidationError("from_outlet_id and to_outlet_id are required")
+	}
+	if transfer.FromOutletID == transfer.ToOutletID {
+		return models.NewValidationError("from_outlet_id and to_outlet_id must differ")
+	}
+	if len(transfer.Items) == 0 {
+		return models.NewValidationError("items must not be empty")
+	}
+	for i, item := range transfer.Items {
+		if item.ProductID <= 0 {
+			return models.NewValidationError("items[%d]: product_id is required", i)
+		}
+		if item.Quantity <= 0 {
+			return models.NewValidationError("items[%d]: quantity must be greater than zero", i)
+		}
+	}
+
+	transfer.Note = strings.TrimSpace(transfer.Note)
+	transfer.CreatedBy = optionalString(operator)
+	if err := s.repo.Create(transfer); err != nil {
+		return err
+	}
+
+	created, err := s.repo.GetByID(transfer.ID)
+	if err != nil {
+		return err
+	}
+	*transfer = *created
+	return nil
+}
+
+func (s *StockTransferService) GetAll(status string) ([]models.StockTransfer, error) {
+	switch status {
+	case "", models.TransferStatusInTransit, models.TransferStatusReceived, models.TransferStatusCancelled:
+	default:
+		return nil, models.NewValidationError("status must be in_transit, received or cancelled")
+	}
+	return s.repo.GetAll(status)
+}
+
+func (s *StockTransferService) GetByID(id int) (*models.StockTransfer, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *StockTransferService) Receive(id int, operator string) (*models.StockTransfer, error) {
+	if err := s.repo.Receive(id, optionalString(operator)); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+func (s *StockTransferService) Cancel(id int, operator string) (*models.StockTransfer, error) {
+	if err := s.repo.Cancel(id, optionalString(operator)); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
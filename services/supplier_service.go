@@ -0,0 +1,78 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+type SupplierService struct {
+	repo *repositories.SupplierRepository
+}
+
+func NewSupplierService(repo *repositories.SupplierRepository) *SupplierService {
+	return &SupplierService{repo: repo}
+}
+
+func (s *SupplierService) GetAll() ([]models.Supplier, error) {
+	return s.repo.GetAll()
+}
+
+func (s *SupplierService) GetByID(id int) (*models.Supplier, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *SupplierService) Create(supplier *models.Supplier) error {
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+	return s.repo.Create(supplier)
+}
+
+func (s *SupplierService) Update(supplier *models.Supplier) error {
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+	return s.repo.Update(supplier)
+}
+
+func (s *SupplierService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+// CreateReturn sends goods back to the supplier, reducing stock
+func (s *SupplierService) CreateReturn(ret *models.SupplierReturn, operator string) error {
+	if len(ret.Items) == 0 {
+		return models.NewValidationError("items must not be empty")
+	}
+	for i, item := range ret.Items {
+		if item.ProductID <= 0 {
+			return models.NewValidationError("items[%d]: product_id is required", i)
+		}
+		if item.Quantity <= 0 {
+			return models.NewValidationError("items[%d]: quantity must be greater than zero", i)
+		}
+		if item.UnitCost < 0 {
+			return models.NewValidationError("items[%d]: unit_cost must not be negative", i)
+		}
+	}
+
+	ret.Note = strings.TrimSpace(ret.Note)
+	ret.CreatedBy = optionalString(operator)
+	return s.repo.CreateReturn(ret)
+}
+
+func (s *SupplierService) GetReturns(supplierID int) ([]models.SupplierReturn, error) {
+	return s.repo.GetReturns(supplierID)
+}
+
+func validateSupplier(supplier *models.Supplier) error {
+	supplier.Name = strings.TrimSpace(supplier.Name)
+	if supplier.Name == "" {
+		return models.NewValidationError("name is required")
+	}
+	supplier.Phone = strings.TrimSpace(supplier.Phone)
+	supplier.Email = strings.TrimSpace(supplier.Email)
+	supplier.Address = strings.TrimSpace(supplier.Address)
+	return nil
+}
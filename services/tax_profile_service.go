@@ -0,0 +1,52 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+type TaxProfileService struct {
+	repo *repositories.TaxProfileRepository
+}
+
+func NewTaxProfileService(repo *repositories.TaxProfileRepository) *TaxProfileService {
+	return &TaxProfileService{repo: repo}
+}
+
+func (s *TaxProfileService) GetAll() ([]models.TaxProfile, error) {
+	return s.repo.GetAll()
+}
+
+func (s *TaxProfileService) GetByID(id int) (*models.TaxProfile, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *TaxProfileService) Create(profile *models.TaxProfile) error {
+	if err := validateTaxProfile(profile); err != nil {
+		return err
+	}
+	return s.repo.Create(profile)
+}
+
+func (s *TaxProfileService) Update(profile *models.TaxProfile) error {
+	if err := validateTaxProfile(profile); err != nil {
+		return err
+	}
+	return s.repo.Update(profile)
+}
+
+func (s *TaxProfileService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+func validateTaxProfile(profile *models.TaxProfile) error {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return models.NewValidationError("name is required")
+	}
+	if profile.Rate < 0 || profile.Rate > 100 {
+		return models.NewValidationError("rate must be between 0 and 100")
+	}
+	return nil
+}
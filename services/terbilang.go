@@ -0,0 +1,74 @@
+package services
+
+import "strings"
+
+var terbilangDigits = []string{"", "satu", "dua", "tiga", "empat", "lima", "enam", "tujuh", "delapan", "sembilan"}
+
+var terbilangScales = []struct {
+	value int
+	name  string
+}{
+	{1_000_000_000_000, "triliun"},
+	{1_000_000_000, "miliar"},
+	{1_000_000, "juta"},
+	{1_000, "ribu"},
+}
+
+// terbilang spells out an amount in Indonesian words, for example 1250000
+// becomes "satu juta dua ratus lima puluh ribu"
+func terbilang(amount int) string {
+	if amount == 0 {
+		return "nol"
+	}
+	if amount < 0 {
+		return "minus " + terbilang(-amount)
+	}
+
+	words := make([]string, 0)
+	for _, scale := range terbilangScales {
+		if amount < scale.value {
+			continue
+		}
+		n := amount / scale.value
+		amount %= scale.value
+		// 1000 adalah "seribu", bukan "satu ribu"
+		if n == 1 && scale.value == 1_000 {
+			words = append(words, "seribu")
+			continue
+		}
+		words = append(words, terbilang(n), scale.name)
+	}
+	if amount > 0 {
+		words = append(words, terbilangHundreds(amount))
+	}
+	return strings.Join(words, " ")
+}
+
+// terbilangHundreds spells out 1 to 999
+func terbilangHundreds(n int) string {
+	words := make([]string, 0)
+	switch hundreds := n / 100; {
+	case hundreds == 1:
+		words = append(words, "seratus")
+	case hundreds > 1:
+		words = append(words, terbilangDigits[hundreds], "ratus")
+	}
+
+	n %= 100
+	switch {
+	case n == 10:
+		words = append(words, "sepuluh")
+	case n == 11:
+		words = append(words, "sebelas")
+	case n > 11 && n < 20:
+		words = append(words, terbilangDigits[n-10], "belas")
+	case n >= 20:
+		words = append(words, terbilangDigits[n/10], "puluh")
+		if n%10 > 0 {
+			words = append(words, terbilangDigits[n%10])
+		}
+	case n > 0:
+		words = append(words, terbilangDigits[n])
+	}
+	return strings.Join(words, " ")
+}
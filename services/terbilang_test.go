@@ -0,0 +1,48 @@
+package services
+
+import "testing"
+
+func TestTerbilang(t *testing.T) {
+	tests := []struct {
+		amount int
+		want   string
+	}{
+		{0, "nol"},
+		{1, "satu"},
+		{9, "sembilan"},
+		{10, "sepuluh"},
+		{11, "sebelas"},
+		{12, "dua belas"},
+		{19, "sembilan belas"},
+		{20, "dua puluh"},
+		{21, "dua puluh satu"},
+		{99, "sembilan puluh sembilan"},
+		{100, "seratus"},
+		{101, "seratus satu"},
+		{110, "seratus sepuluh"},
+		{111, "seratus sebelas"},
+		{200, "dua ratus"},
+		{999, "sembilan ratus sembilan puluh sembilan"},
+		{1000, "seribu"},
+		{1001, "seribu satu"},
+		{1100, "seribu seratus"},
+		{2000, "dua ribu"},
+		{11000, "sebelas ribu"},
+		{100000, "seratus ribu"},
+		{101000, "seratus satu ribu"},
+		{999999, "sembilan ratus sembilan puluh sembilan ribu sembilan ratus sembilan puluh sembilan"},
+		{1000000, "satu juta"},
+		{1001000, "satu juta seribu"},
+		{1250000, "satu juta dua ratus lima puluh ribu"},
+		{1000000000, "satu miliar"},
+		{1000000000000, "satu triliun"},
+		{1000000000000000, "seribu triliun"},
+		{-1, "minus satu"},
+		{-15500, "minus lima belas ribu lima ratus"},
+	}
+	for _, tt := range tests {
+		if got := terbilang(tt.amount); got != tt.want {
+			t.Errorf("terbilang(%d) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
@@ -1,26 +1,262 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"kasir-api/models"
 	"kasir-api/repositories"
+	"strings"
+	"time"
 )
 
 type TransactionService struct {
-	repo *repositories.TransactionRepository
+	repo                 *repositories.TransactionRepository
+	voidWindow           time.Duration
+	idempotencyRetention time.Duration
+	loyalty              models.LoyaltyConfig
 }
 
-func NewTransactionService(repo *repositories.TransactionRepository) *TransactionService {
-	return &TransactionService{repo: repo}
+// NewTransactionService - voidWindow is how long after a sale it may be
+// voided, zero allows voids until the end of the business day.
+// idempotencyRetention is how long checkout idempotency keys are kept.
+// loyalty sets how customers earn and redeem points.
+func NewTransactionService(repo *repositories.TransactionRepository, voidWindow, idempotencyRetention time.Duration, loyalty models.LoyaltyConfig) *TransactionService {
+	return &TransactionService{repo: repo, voidWindow: voidWindow, idempotencyRetention: idempotencyRetention, loyalty: loyalty}
 }
 
-func (s *TransactionService) Checkout(items []models.CheckoutItem) (*models.Transaction, error) {
-	return s.repo.CreateTransaction(items)
+const maxIdempotencyKeyLength = 255
+
+const (
+	defaultTransactionPageSize = 50
+	maxTransactionPageSize     = 200
+)
+
+func (s *TransactionService) GetAll(filter models.TransactionFilter) (*models.Page[models.TransactionSummary], error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultTransactionPageSize
+	}
+	if filter.Limit > maxTransactionPageSize {
+		filter.Limit = maxTransactionPageSize
+	}
+	if filter.Offset < 0 {
+		return nil, models.NewValidationError("offset must not be negative")
+	}
+	for key, value := range map[string]string{"start_date": filter.StartDate, "end_date": filter.EndDate} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return nil, models.NewValidationError("%s must be a date in YYYY-MM-DD format", key)
+		}
+	}
+	if filter.StartDate != "" && filter.EndDate != "" && filter.StartDate > filter.EndDate {
+		return nil, models.NewValidationError("start_date must not be after end_date")
+	}
+	if filter.MinTotal != nil && filter.MaxTotal != nil && *filter.MinTotal > *filter.MaxTotal {
+		return nil, models.NewValidationError("min_total must not be greater than max_total")
+	}
+	switch filter.Status {
+	case "", models.TransactionStatusCompleted, models.TransactionStatusRefunded, models.TransactionStatusVoided, models.TransactionStatusRefund:
+	default:
+		return nil, models.NewValidationError("status must be one of completed, refunded, voided, refund")
+	}
+
+	transactions, total, err := s.repo.GetAll(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewPage(transactions, total, filter.Limit, filter.Offset), nil
+}
+
+func (s *TransactionService) GetByID(id int) (*models.Transaction, error) {
+	return s.repo.GetByID(id)
+}
+
+// Checkout sells the items from the stock of outletID. idempotency is nil
+// when the request has no Idempotency-Key.
+func (s *TransactionService) Checkout(outletID int, req models.CheckoutRequest, operator string, idempotency *models.IdempotencyKey) (*models.Transaction, error) {
+	if len(req.Items) == 0 {
+		return nil, models.NewValidationError("items must not be empty")
+	}
+	for i, item := range req.Items {
+		if item.ProductID <= 0 && item.Barcode == "" {
+			return nil, models.NewValidationError("items[%d]: product_id or barcode is required", i)
+		}
+		if item.ProductID > 0 && item.Barcode != "" {
+			return nil, models.NewValidationError("items[%d]: use either product_id or barcode, not both", i)
+		}
+		if item.Quantity <= 0 {
+			return nil, models.NewValidationError("items[%d]: quantity must be greater than zero", i)
+		}
+	}
+
+	// payment_method + amount_tendered adalah bentuk singkat dari satu payment
+	if len(req.Payments) == 0 {
+		req.Payments = []models.CheckoutPayment{{Method: req.PaymentMethod, Amount: req.AmountTendered}}
+	} else if req.PaymentMethod != "" || req.AmountTendered != nil {
+		return nil, models.NewValidationError("use either payments or payment_method and amount_tendered, not both")
+	}
+
+	for i := range req.Payments {
+		payment := &req.Payments[i]
+		payment.Method = strings.ToLower(strings.TrimSpace(payment.Method))
+		if payment.Method == "" {
+			payment.Method = models.PaymentCash
+		}
+		if !isPaymentMethod(payment.Method) {
+			return nil, models.NewValidationError("payments[%d]: method must be cash, debit, credit, qris, ewallet or transfer", i)
+		}
+		if payment.Amount == nil && len(req.Payments) > 1 {
+			return nil, models.NewValidationError("payments[%d]: amount is required when paying with more than one payment", i)
+		}
+		if payment.Amount != nil && *payment.Amount <= 0 {
+			return nil, models.NewValidationError("payments[%d]: amount must be greater than zero", i)
+		}
+	}
+
+	req.CouponCode = strings.ToUpper(strings.TrimSpace(req.CouponCode))
+
+	if req.RedeemPoints < 0 {
+		return nil, models.NewValidationError("redeem_points must not be negative")
+	}
+	if req.RedeemPoints > 0 && req.CustomerID == nil {
+		return nil, models.NewValidationError("customer_id is required to redeem points")
+	}
+	if req.RedeemPoints > 0 && s.loyalty.PointValue <= 0 {
+		return nil, models.NewValidationError("redeeming points is not enabled")
+	}
+
+	if req.Buyer != nil {
+		buyer, err := normalizeBuyer(*req.Buyer)
+		if err != nil {
+			return nil, err
+		}
+		req.Buyer = buyer
+	}
+
+	if idempotency != nil {
+		idempotency.Key = strings.TrimSpace(idempotency.Key)
+		if idempotency.Key == "" || len(idempotency.Key) > maxIdempotencyKeyLength {
+			return nil, models.NewValidationError("Idempotency-Key must be 1 to %d characters", maxIdempotencyKeyLength)
+		}
+
+		// fingerprint dari request yang sudah dinormalisasi plus outlet dan
+		// operator, key milik user lain tidak pernah mengembalikan transaksinya
+		encoded, err := json.Marshal(struct {
+			OutletID int                    `json:"outlet_id"`
+			Operator string                 `json:"operator"`
+			Request  models.CheckoutRequest `json:"request"`
+		}{outletID, operator, req})
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(encoded)
+		idempotency.Fingerprint = hex.EncodeToString(sum[:])
+		idempotency.Retention = s.idempotencyRetention
+	}
+
+	return s.repo.CreateTransaction(outletID, req, optionalString(operator), idempotency, s.loyalty)
 }
 
-func (s *TransactionService) GetTodaySalesSummary() (*models.DailySalesSummary, error) {
-	return s.repo.GetTodaySalesSummary()
+// Refund returns items of transaction id
+func (s *TransactionService) Refund(id int, req models.RefundRequest, operator string) (*models.Transaction, error) {
+	if len(req.Items) == 0 {
+		return nil, models.NewValidationError("items must not be empty")
+	}
+	for i, item := range req.Items {
+		if item.DetailID <= 0 && item.ProductID <= 0 {
+			return nil, models.NewValidationError("items[%d]: detail_id or product_id is required", i)
+		}
+		if item.DetailID > 0 && item.ProductID > 0 {
+			return nil, models.NewValidationError("items[%d]: use either detail_id or product_id, not both", i)
+		}
+		if item.Quantity <= 0 {
+			return nil, models.NewValidationError("items[%d]: quantity must be greater than zero", i)
+		}
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return nil, models.NewValidationError("reason is required")
+	}
+	req.PaymentMethod = strings.ToLower(strings.TrimSpace(req.PaymentMethod))
+	if req.PaymentMethod != "" && !isPaymentMethod(req.PaymentMethod) {
+		return nil, models.NewValidationError("payment_method must be cash, debit, credit, qris, ewallet or transfer")
+	}
+
+	return s.repo.CreateRefund(id, req, optionalString(operator), s.loyalty.Expiry)
+}
+
+// Void cancels transaction id, the operator is recorded as who voided it
+func (s *TransactionService) Void(id int, req models.VoidRequest, operator string) (*models.Transaction, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return nil, models.NewValidationError("reason is required")
+	}
+	if operator == "" {
+		return nil, models.NewValidationError("operator is required to void a transaction")
+	}
+
+	return s.repo.VoidTransaction(id, req.Reason, &operator, s.voidWindow, s.loyalty.Expiry)
+}
+
+func (s *TransactionService) GetTodaySalesSummary(outletID *int) (*models.DailySalesSummary, error) {
+	return s.repo.GetTodaySalesSummary(outletID)
+}
+
+func (s *TransactionService) GetSalesSummaryByDateRange(startDate, endDate string, outletID *int) (*models.DailySalesSummary, error) {
+	return s.repo.GetSalesSummaryByDateRange(startDate, endDate, outletID)
+}
+
+func (s *TransactionService) GetProductSales(startDate, endDate string, outletID *int) ([]models.ProductSales, error) {
+	return s.repo.GetProductSales(startDate, endDate, outletID)
+}
+
+func (s *TransactionService) GetTaxReport(startDate, endDate string, outletID *int) (*models.TaxReport, error) {
+	return s.repo.GetTaxReport(startDate, endDate, outletID)
+}
+
+// normalizeBuyer trims the buyer and reduces the NPWP to its digits. An
+// empty buyer is dropped.
+func normalizeBuyer(buyer models.Buyer) (*models.Buyer, error) {
+	buyer.Name = strings.TrimSpace(buyer.Name)
+	buyer.Address = strings.TrimSpace(buyer.Address)
+	buyer.NPWP = strings.Map(func(r rune) rune {
+		// NPWP biasa ditulis 01.234.567.8-901.000
+		if r == '.' || r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, buyer.NPWP)
+
+	if buyer == (models.Buyer{}) {
+		return nil, nil
+	}
+	if buyer.Name == "" {
+		return nil, models.NewValidationError("buyer.name is required")
+	}
+	if len(buyer.Name) > 255 {
+		return nil, models.NewValidationError("buyer.name must be at most 255 characters")
+	}
+	if buyer.NPWP != "" {
+		if len(buyer.NPWP) != 15 && len(buyer.NPWP) != 16 {
+			return nil, models.NewValidationError("buyer.npwp must have 15 or 16 digits")
+		}
+		for _, r := range buyer.NPWP {
+			if r < '0' || r > '9' {
+				return nil, models.NewValidationError("buyer.npwp must only contain digits")
+			}
+		}
+	}
+	return &buyer, nil
 }
 
-func (s *TransactionService) GetSalesSummaryByDateRange(startDate, endDate string) (*models.DailySalesSummary, error) {
-	return s.repo.GetSalesSummaryByDateRange(startDate, endDate)
+func isPaymentMethod(method string) bool {
+	switch method {
+	case models.PaymentCash, models.PaymentDebit, models.PaymentCredit, models.PaymentQRIS, models.PaymentEWallet, models.PaymentTransfer:
+		return true
+	}
+	return false
 }
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type UserService struct {
+	repo *repositories.UserRepository
+}
+
+func NewUserService(repo *repositories.UserRepository) *UserService {
+	return &UserService{repo: repo}
+}
+
+const minPasswordLength = 8
+
+func (s *UserService) GetAll() ([]models.User, error) {
+	return s.repo.GetAll()
+}
+
+func (s *UserService) GetByID(id int) (*models.User, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *UserService) Create(req models.UserRequest) (*models.User, error) {
+	user := &models.User{Active: true}
+	if req.Active != nil {
+		user.Active = *req.Active
+	}
+	if req.Password == "" {
+		return nil, models.NewValidationError("password is required")
+	}
+	if err := applyUserRequest(user, req); err != nil {
+		return nil, err
+	}
+	roles, err := normalizeRoleNames(req.Roles)
+	if err != nil {
+		return nil, err
+	}
+	user.Roles = roles
+
+	if err := s.repo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *UserService) Update(id int, req models.UserRequest) (*models.User, error) {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	// username tercatat di shift dan transaksi, tidak boleh diganti
+	username := strings.TrimSpace(req.Username)
+	if username != "" && username != user.Username {
+		return nil, models.NewValidationError("username cannot be changed, it is recorded on shifts and transactions")
+	}
+	req.Username = user.Username
+
+	if req.Active != nil {
+		user.Active = *req.Active
+	}
+	if err := applyUserRequest(user, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetRoles replaces the roles of user id
+func (s *UserService) SetRoles(id int, req models.UserRolesRequest) (*models.User, error) {
+	roles, err := normalizeRoleNames(req.Roles)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetRoles(id, roles); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// Bootstrap creates the first user as owner when there are none yet, so a
+// fresh install can log in
+func (s *UserService) Bootstrap(username, password string) (*models.User, error) {
+	count, err := s.repo.Count()
+	if err != nil || count > 0 || username == "" {
+		return nil, err
+	}
+	return s.Create(models.UserRequest{Username: username, Password: password, Roles: []string{models.RoleOwner}})
+}
+
+func normalizeRoleNames(names []string) ([]string, error) {
+	roles := make([]string, 0, len(names))
+	for _, name := range names {
+		name, err := normalizeRoleName(name)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(roles, name) {
+			roles = append(roles, name)
+		}
+	}
+	return roles, nil
+}
+
+// applyUserRequest validates the request and hashes a new password
+func applyUserRequest(user *models.User, req models.UserRequest) error {
+	user.Username = strings.TrimSpace(req.Username)
+	if user.Username == "" {
+		return models.NewValidationError("username is required")
+	}
+	if len(user.Username) > 50 || strings.ContainsAny(user.Username, " \t\r\n") {
+		return models.NewValidationError("username must be at most 50 characters without spaces")
+	}
+
+	if req.Password == "" {
+		return nil
+	}
+	if len(req.Password) < minPasswordLength {
+		return models.NewValidationError("password must be at least %d characters", minPasswordLength)
+	}
+	// bcrypt hanya memakai 72 byte pertama
+	if len(req.Password) > 72 {
+		return models.NewValidationError("password must be at most 72 bytes")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = string(hash)
+	return nil
+}